@@ -0,0 +1,124 @@
+package call
+
+import (
+	"context"
+	"sync"
+)
+
+// Future is the result of an asynchronous call.
+type Future struct {
+	done    chan struct{}
+	returns []any
+	err     error
+}
+
+// Go calls function with name and arguments in a new goroutine.
+//
+// Without args, registered arguments of the function are used like Call and CallSpec.
+func (r *Reg) Go(name string, args ...string) *Future {
+	return r.goCall(context.Background(), false, name, args)
+}
+
+// GoContext is like Go but calls the function like CallWithArgsContext.
+func (r *Reg) GoContext(ctx context.Context, name string, args ...string) *Future {
+	return r.goCall(ctx, true, name, args)
+}
+
+func (r *Reg) goCall(ctx context.Context, withContext bool, name string, args []string) *Future {
+	f := &Future{done: make(chan struct{})}
+
+	go func() {
+		defer close(f.done)
+
+		if args == nil {
+			fn, _ := r.GetFunction(name)
+			args = fn.Args
+		}
+
+		f.returns, f.err = r.callWithArgs(ctx, withContext, name, args)
+	}()
+
+	return f
+}
+
+// Done returns channel which is closed when call is finished.
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait waits the call and returns its result, it returns ctx error if ctx is done before.
+//
+// Call continues when ctx is done, it is not canceled.
+func (f *Future) Wait(ctx context.Context) ([]any, error) {
+	select {
+	case <-f.done:
+		return f.returns, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Result waits the call and returns its result.
+func (f *Future) Result() ([]any, error) {
+	<-f.done
+
+	return f.returns, f.err
+}
+
+// CallSpec is a function call of CallAll, nil Args means registered arguments of the function.
+type CallSpec struct {
+	Name string
+	Args []string
+}
+
+// Result is returns and error of a call.
+type Result struct {
+	Returns []any
+	Err     error
+}
+
+// CallAll calls functions concurrently with at most workers goroutines.
+//
+// Results are in the same order with calls, workers less than 1 means one goroutine per call.
+// Calls which are not started when ctx is done return ctx error.
+func (r *Reg) CallAll(ctx context.Context, workers int, calls ...CallSpec) []Result {
+	results := make([]Result, len(calls))
+
+	if workers < 1 || workers > len(calls) {
+		workers = len(calls)
+	}
+
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+
+	wg.Add(workers)
+
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				c := calls[i]
+
+				args := c.Args
+				if args == nil {
+					f, _ := r.GetFunction(c.Name)
+					args = f.Args
+				}
+
+				returns, err := r.CallWithArgsContext(ctx, c.Name, args...)
+				results[i] = Result{Returns: returns, Err: err}
+			}
+		}()
+	}
+
+	for i := range calls {
+		indexes <- i
+	}
+
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
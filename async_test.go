@@ -0,0 +1,136 @@
+package call
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestReg_Go(t *testing.T) {
+	release := make(chan struct{})
+
+	reg := NewReg().
+		AddArgument("a", 2).
+		AddFunction("slow", func(a int) int {
+			<-release
+
+			return a * 2
+		}).
+		AddFunction("ctx", func(ctx context.Context) error { return ctx.Err() })
+
+	f := reg.Go("slow", "a")
+
+	select {
+	case <-f.Done():
+		t.Fatal("future is done before release")
+	default:
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := f.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Future.Wait() error = %v, want deadline exceeded", err)
+	}
+
+	close(release)
+
+	got, err := f.Wait(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, []any{4}) {
+		t.Errorf("Future.Wait() = %v, want [4]", got)
+	}
+
+	if got, err := f.Result(); err != nil || !reflect.DeepEqual(got, []any{4}) {
+		t.Errorf("Future.Result() = %v, %v", got, err)
+	}
+
+	// registered arguments are used without args
+	reg.AddFunction("double", func(a int) int { return a * 2 }, "a")
+
+	if got, err := reg.Go("double").Result(); err != nil || !reflect.DeepEqual(got, []any{4}) {
+		t.Errorf("Future.Result() = %v, %v, want [4]", got, err)
+	}
+
+	if _, err := reg.Go("missing").Result(); !errors.Is(err, ErrFunctionNotFound) {
+		t.Errorf("Future.Result() error = %v, want function not found", err)
+	}
+
+	canceled, cancelCall := context.WithCancel(context.Background())
+	cancelCall()
+
+	if _, err := reg.GoContext(canceled, "ctx").Result(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Future.Result() error = %v, want canceled", err)
+	}
+}
+
+func TestReg_CallAll(t *testing.T) {
+	var running, maxRunning atomic.Int32
+
+	reg := NewReg().
+		AddArgument("a", 1).
+		AddArgument("b", 2).
+		AddFunction("work", func(v int) int {
+			n := running.Add(1)
+			defer running.Add(-1)
+
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+
+			time.Sleep(5 * time.Millisecond)
+
+			return v * 10
+		}, "a")
+
+	calls := []CallSpec{
+		{Name: "work"},
+		{Name: "work", Args: []string{"b"}},
+		{Name: "missing"},
+		{Name: "work", Args: []string{"a"}},
+		{Name: "work", Args: []string{"b"}},
+	}
+
+	results := reg.CallAll(context.Background(), 2, calls...)
+
+	want := []any{10, 20, nil, 10, 20}
+	for i, result := range results {
+		if want[i] == nil {
+			if !errors.Is(result.Err, ErrFunctionNotFound) {
+				t.Errorf("result %d error = %v, want function not found", i, result.Err)
+			}
+
+			continue
+		}
+
+		if result.Err != nil || !reflect.DeepEqual(result.Returns, []any{want[i]}) {
+			t.Errorf("result %d = %+v, want %v", i, result, want[i])
+		}
+	}
+
+	if m := maxRunning.Load(); m > 2 {
+		t.Errorf("max running = %d, want at most 2", m)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for i, result := range reg.CallAll(ctx, 0, calls[:2]...) {
+		if !errors.Is(result.Err, context.Canceled) {
+			t.Errorf("canceled result %d error = %v", i, result.Err)
+		}
+	}
+
+	if results := reg.CallAll(context.Background(), 3); len(results) != 0 {
+		t.Errorf("empty CallAll() = %v", results)
+	}
+}
@@ -0,0 +1,136 @@
+package call
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// AddFunctionAuto adds function to registry with name and resolves its parameters by type.
+//
+// Each parameter gets the argument or provider which has the same type, if there is no
+// same type it uses assignable one. More than one candidate returns ErrAmbiguousArgument.
+//
+// Overrides are argument names by parameter position, empty string means autowire.
+//
+//	reg.AddFunctionAuto("handler", handler, "", "replicaDB")
+//
+// Variadic parameter only gets overrides, context parameters when calling with context and
+// struct parameters with `call` tags are skipped.
+// If name is empty, function name will be used.
+// Argument must be a function, otherwise it will panic.
+func (r *Reg) AddFunctionAuto(name string, fn any, overrides ...string) *Reg {
+	return r.addFunction(name, fn, overrides, true)
+}
+
+// autowire returns argument names for the function's parameters.
+func (r *Reg) autowire(state *callState, fnType reflect.Type, overrides []string) ([]string, error) {
+	numIn := fnType.NumIn()
+	if fnType.IsVariadic() {
+		numIn--
+	}
+
+	args := make([]string, 0, numIn)
+	for i := 0; i < numIn; i++ {
+		if i < len(overrides) && overrides[i] != "" {
+			args = append(args, overrides[i])
+
+			continue
+		}
+
+		paramType := fnType.In(i)
+		if (state.withContext && paramType == contextType) || isInjectable(paramType) {
+			continue
+		}
+
+		name, err := r.findByType(paramType)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %d: %w", i, err)
+		}
+
+		args = append(args, name)
+	}
+
+	// variadic part
+	if len(overrides) > numIn {
+		for _, override := range overrides[numIn:] {
+			if override != "" {
+				args = append(args, override)
+			}
+		}
+	}
+
+	return args, nil
+}
+
+// findByType returns name of the argument or provider which matches with t.
+func (r *Reg) findByType(t reflect.Type) (string, error) {
+	var exact, assignable []string
+
+	match := func(name string, vType reflect.Type) {
+		switch {
+		case vType == t:
+			exact = append(exact, name)
+		case vType.AssignableTo(t):
+			assignable = append(assignable, name)
+		}
+	}
+
+	// names of the nearer registry hide parent's names
+	seen := make(map[string]struct{})
+
+	for reg := r; reg != nil; reg = reg.parent {
+		reg.mutex.RLock()
+
+		for name, v := range reg.args {
+			if _, ok := seen[name]; !ok && v != nil {
+				match(name, reflect.TypeOf(v))
+			}
+		}
+
+		for name, p := range reg.providers {
+			if _, ok := seen[name]; ok {
+				continue
+			}
+
+			if vType, ok := providerType(p.Fn.Type()); ok {
+				match(name, vType)
+			}
+		}
+
+		for name := range reg.args {
+			seen[name] = struct{}{}
+		}
+
+		for name := range reg.providers {
+			seen[name] = struct{}{}
+		}
+
+		reg.mutex.RUnlock()
+	}
+
+	candidates := exact
+	if len(candidates) == 0 {
+		candidates = assignable
+	}
+
+	switch len(candidates) {
+	case 0:
+		return "", fmt.Errorf("%w: type %s", ErrArgumentNotFound, t)
+	case 1:
+		return candidates[0], nil
+	}
+
+	sort.Strings(candidates)
+
+	return "", fmt.Errorf("%w: type %s candidates %v", ErrAmbiguousArgument, t, candidates)
+}
+
+// providerType returns type of the value which provider function returns.
+func providerType(fnType reflect.Type) (reflect.Type, bool) {
+	if fnType.NumOut() == 0 || fnType.Out(0) == errorType {
+		return nil, false
+	}
+
+	return fnType.Out(0), true
+}
@@ -0,0 +1,127 @@
+package call
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type testStringer string
+
+func (s testStringer) String() string { return string(s) }
+
+func TestReg_AddFunctionAuto(t *testing.T) {
+	tests := []struct {
+		name       string
+		modify     func(*Reg)
+		overrides  []string
+		fn         any
+		ctx        context.Context
+		want       []any
+		wantErr    error
+		wantErrStr string
+	}{
+		{
+			name: "by type",
+			modify: func(r *Reg) {
+				r.AddArgument("name", "test").AddArgument("count", 3)
+			},
+			fn:   func(count int, name string) string { return fmt.Sprint(name, count) },
+			want: []any{"test3"},
+		},
+		{
+			name: "provider by type",
+			modify: func(r *Reg) {
+				r.AddArgument("name", "test").AddProvider("count", func() (int, error) { return 4, nil })
+			},
+			fn:   func(count int, name string) string { return fmt.Sprint(name, count) },
+			want: []any{"test4"},
+		},
+		{
+			name: "exact type before assignable",
+			modify: func(r *Reg) {
+				r.AddArgument("stringer", testStringer("stringer")).
+					AddProvider("interface", func() fmt.Stringer { return testStringer("interface") })
+			},
+			fn:   func(v fmt.Stringer) string { return v.String() },
+			want: []any{"interface"},
+		},
+		{
+			name: "assignable",
+			modify: func(r *Reg) {
+				r.AddArgument("stringer", testStringer("stringer")).AddArgument("count", 3)
+			},
+			fn:   func(v fmt.Stringer) string { return v.String() },
+			want: []any{"stringer"},
+		},
+		{
+			name: "ambiguous",
+			modify: func(r *Reg) {
+				r.AddArgument("primary", "primary").AddArgument("replica", "replica")
+			},
+			fn:         func(v string) string { return v },
+			wantErr:    ErrAmbiguousArgument,
+			wantErrStr: "resolve function test: parameter 0: ambiguous argument: type string candidates [primary replica]",
+		},
+		{
+			name: "override",
+			modify: func(r *Reg) {
+				r.AddArgument("primary", "primary").AddArgument("replica", "replica").AddArgument("count", 3)
+			},
+			overrides: []string{"", "replica"},
+			fn:        func(count int, v string) string { return fmt.Sprint(v, count) },
+			want:      []any{"replica3"},
+		},
+		{
+			name:       "not found",
+			fn:         func(v string) string { return v },
+			wantErr:    ErrArgumentNotFound,
+			wantErrStr: "resolve function test: parameter 0: argument not found: type string",
+		},
+		{
+			name: "context and variadic",
+			modify: func(r *Reg) {
+				r.AddArgument("name", "test").AddArgument("list", []int{1, 2})
+			},
+			overrides: []string{"", "", "list:..."},
+			ctx:       context.Background(),
+			fn: func(ctx context.Context, name string, v ...int) string {
+				return fmt.Sprint(name, v)
+			},
+			want: []any{"test[1 2]"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewReg().AddFunctionAuto("test", tt.fn, tt.overrides...)
+			if tt.modify != nil {
+				tt.modify(r)
+			}
+
+			var (
+				got []any
+				err error
+			)
+			if tt.ctx != nil {
+				got, err = r.CallContext(tt.ctx, "test")
+			} else {
+				got, err = r.Call("test")
+			}
+
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Reg.Call() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErrStr != "" && (err == nil || err.Error() != tt.wantErrStr) {
+				t.Errorf("Reg.Call() error = %v, wantErrStr %v", err, tt.wantErrStr)
+			}
+			if tt.wantErrStr == "" && err != nil {
+				t.Errorf("Reg.Call() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Reg.Call() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
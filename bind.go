@@ -0,0 +1,112 @@
+package call
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// bindSeparator separates function and target names in bootstrap steps.
+const bindSeparator = "->"
+
+// CallInto calls function with registered arguments and adds returns as arguments with targets.
+//
+// Trailing error is not stored and returned as error, nothing is added when call fails.
+// Target `_` or empty target skips the return value, targets can be fewer than returns.
+func (r *Reg) CallInto(name string, targets ...string) ([]any, error) {
+	return r.callInto(context.Background(), false, name, targets)
+}
+
+// CallIntoContext is like CallInto but calls the function like CallContext.
+func (r *Reg) CallIntoContext(ctx context.Context, name string, targets ...string) ([]any, error) {
+	return r.callInto(ctx, true, name, targets)
+}
+
+func (r *Reg) callInto(ctx context.Context, withContext bool, name string, targets []string) ([]any, error) {
+	f, ok := r.GetFunction(name)
+
+	// check targets before calling, so a failing call doesn't run the function
+	if ok {
+		if n := numReturns(f.Fn.Type()); len(targets) > n {
+			return nil, fmt.Errorf("function %s returns %d values, got %d targets", name, n, len(targets))
+		}
+	}
+
+	returnV, err := r.call(ctx, withContext, name, f.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	returnV, err = splitError(returnV)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(targets) > len(returnV) {
+		return nil, fmt.Errorf("function %s returns %d values, got %d targets", name, len(returnV), len(targets))
+	}
+
+	returns := interfaces(returnV)
+
+	for i, target := range targets {
+		if target == "" || target == "_" {
+			continue
+		}
+
+		r.AddArgument(target, returns[i])
+	}
+
+	return returns, nil
+}
+
+// numReturns returns number of the function's returns without trailing error.
+func numReturns(t reflect.Type) int {
+	n := t.NumOut()
+	if n > 0 && t.Out(n-1) == errorType {
+		n--
+	}
+
+	return n
+}
+
+// Bootstrap calls steps in order and stores returns as arguments, it stops at first error.
+//
+// Step is a function name and targets of the returns separated with `->`.
+//
+//	reg.Bootstrap("loadConfig -> config", "openDB -> db", "migrate", "newServer -> server, _")
+func (r *Reg) Bootstrap(steps ...string) error {
+	return r.bootstrap(context.Background(), false, steps)
+}
+
+// BootstrapContext is like Bootstrap but calls functions like CallContext.
+func (r *Reg) BootstrapContext(ctx context.Context, steps ...string) error {
+	return r.bootstrap(ctx, true, steps)
+}
+
+func (r *Reg) bootstrap(ctx context.Context, withContext bool, steps []string) error {
+	for i, step := range steps {
+		name, targets := parseBindStep(step)
+
+		if _, err := r.callInto(ctx, withContext, name, targets); err != nil {
+			return fmt.Errorf("bootstrap step %d %s: %w", i, name, err)
+		}
+	}
+
+	return nil
+}
+
+// parseBindStep returns function name and targets of the step.
+func parseBindStep(step string) (string, []string) {
+	name, targetList, ok := strings.Cut(step, bindSeparator)
+	if !ok {
+		return strings.TrimSpace(step), nil
+	}
+
+	targets := strings.Split(targetList, ",")
+	for i := range targets {
+		targets[i] = strings.TrimSpace(targets[i])
+	}
+
+	return strings.TrimSpace(name), targets
+}
@@ -0,0 +1,137 @@
+package call
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestReg_CallInto(t *testing.T) {
+	errLoad := errors.New("load failed")
+
+	tests := []struct {
+		name       string
+		fn         any
+		targets    []string
+		want       []any
+		wantArgs   map[string]any
+		wantErr    error
+		wantErrStr string
+	}{
+		{
+			name:     "returns with trailing error",
+			fn:       func() (string, int, error) { return "host", 80, nil },
+			targets:  []string{"host", "port"},
+			want:     []any{"host", 80},
+			wantArgs: map[string]any{"host": "host", "port": 80},
+		},
+		{
+			name:     "skip targets",
+			fn:       func() (string, int) { return "host", 80 },
+			targets:  []string{"_", "port"},
+			want:     []any{"host", 80},
+			wantArgs: map[string]any{"port": 80},
+		},
+		{
+			name:     "fewer targets",
+			fn:       func() (string, int) { return "host", 80 },
+			targets:  []string{"host"},
+			want:     []any{"host", 80},
+			wantArgs: map[string]any{"host": "host"},
+		},
+		{
+			name:       "returned error",
+			fn:         func() (string, error) { return "", errLoad },
+			targets:    []string{"host"},
+			wantErr:    errLoad,
+			wantErrStr: "load failed",
+		},
+		{
+			name:       "more targets",
+			fn:         func() string { return "host" },
+			targets:    []string{"host", "port"},
+			wantErrStr: "function fn returns 1 values, got 2 targets",
+		},
+		{
+			name:       "more targets with trailing error",
+			fn:         func() (string, error) { panic("called with more targets") },
+			targets:    []string{"host", "err"},
+			wantErrStr: "function fn returns 1 values, got 2 targets",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := NewReg().AddFunction("fn", tt.fn)
+
+			got, err := reg.CallInto("fn", tt.targets...)
+			if err != nil {
+				if err.Error() != tt.wantErrStr {
+					t.Errorf("Reg.CallInto() error = %v, wantErrStr %v", err, tt.wantErrStr)
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("Reg.CallInto() error = %v, want %v", err, tt.wantErr)
+				}
+				if names := reg.GetArgumentNames(); len(names) != 0 {
+					t.Errorf("arguments added on error: %v", names)
+				}
+				return
+			}
+			if tt.wantErrStr != "" {
+				t.Fatalf("Reg.CallInto() error = nil, wantErrStr %v", tt.wantErrStr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Reg.CallInto() = %v, want %v", got, tt.want)
+			}
+
+			args := map[string]any{}
+			for _, name := range reg.GetArgumentNames() {
+				args[name], _ = reg.GetArgument(name)
+			}
+
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("arguments = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestReg_Bootstrap(t *testing.T) {
+	type config struct {
+		DSN string
+	}
+
+	type db struct {
+		dsn string
+	}
+
+	var migrated bool
+
+	reg := NewReg().
+		AddFunction("loadConfig", func() (config, error) { return config{DSN: "postgres://"}, nil }).
+		AddFunction("openDB", func(cfg config) (*db, error) { return &db{dsn: cfg.DSN}, nil }, "config").
+		AddFunction("migrate", func(ctx context.Context, d *db) error {
+			migrated = ctx != nil && d.dsn == "postgres://"
+
+			return nil
+		}, "db").
+		AddFunction("server", func(d *db) (string, int) { return "server " + d.dsn, 8080 }, "db")
+
+	err := reg.BootstrapContext(context.Background(), "loadConfig -> config", "openDB->db", "migrate", "server -> server, _")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !migrated {
+		t.Error("migrate is not called")
+	}
+
+	if v, _ := reg.GetArgument("server"); v != "server postgres://" {
+		t.Errorf("server = %v", v)
+	}
+
+	err = reg.Bootstrap("loadConfig -> config", "missing -> x")
+	if !errors.Is(err, ErrFunctionNotFound) || err.Error() != "bootstrap step 1 missing: resolve function missing: function not found" {
+		t.Errorf("Reg.Bootstrap() error = %v", err)
+	}
+}
@@ -1,89 +1,529 @@
 package call
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
 )
 
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
 // Call calls function with name and uses already registered arguments.
 func (r *Reg) Call(name string) ([]any, error) {
-	return r.CallWithArgs(name, r.fn[name].Args...)
+	f, _ := r.GetFunction(name)
+
+	return r.CallWithArgs(name, f.Args...)
 }
 
 // CallWithArgs calls function with name and arguments.
 func (r *Reg) CallWithArgs(name string, args ...string) ([]any, error) {
-	r.mutex.RLock()
-	defer r.mutex.RUnlock()
+	return r.callWithArgs(context.Background(), false, name, args)
+}
 
-	f, ok := r.fn[name]
+// CallContext calls function with name and uses already registered arguments.
+//
+// ctx passed to the function's context.Context parameters and option functions.
+func (r *Reg) CallContext(ctx context.Context, name string) ([]any, error) {
+	f, _ := r.GetFunction(name)
 
-	if !ok {
-		return nil, fmt.Errorf("function %s not found", name)
+	return r.CallWithArgsContext(ctx, name, f.Args...)
+}
+
+// CallWithArgsContext calls function with name and arguments.
+//
+// Every context.Context parameter of the function is filled with ctx, so arguments
+// should not be given for these positions.
+// Call returns ctx error if ctx is done before invoking the function.
+func (r *Reg) CallWithArgsContext(ctx context.Context, name string, args ...string) ([]any, error) {
+	return r.callWithArgs(ctx, true, name, args)
+}
+
+func (r *Reg) callWithArgs(ctx context.Context, withContext bool, name string, args []string) ([]any, error) {
+	returnV, err := r.call(ctx, withContext, name, args)
+	if err != nil {
+		return nil, err
 	}
 
-	fnArgs := make([]reflect.Value, 0)
-	// get arguments
+	return interfaces(returnV), nil
+}
+
+// interfaces converts return values to []any.
+func interfaces(values []reflect.Value) []any {
+	returns := make([]any, len(values))
+	for i, v := range values {
+		returns[i] = v.Interface()
+	}
+
+	return returns
+}
+
+// callState is shared through nested calls of the providers.
+type callState struct {
+	ctx         context.Context
+	withContext bool
+	// path is the provider names in resolving order, used to detect cycles.
+	path []string
+	// root is set when a singleton is resolved, nested calls share it.
+	root *callRoot
+}
+
+// call resolves arguments and calls the function, it returns raw return values.
+func (r *Reg) call(ctx context.Context, withContext bool, name string, args []string) ([]reflect.Value, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, newCallError(StageResolve, name, "", err)
+	}
+
+	state := &callState{ctx: ctx, withContext: withContext}
+
+	c, err := r.cachedCompile(state, name, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.execute(state, name, c)
+}
+
+func (r *Reg) callFunc(state *callState, name string, f Func, args []string) ([]reflect.Value, error) {
+	c, err := r.compile(state, name, f, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.execute(state, name, c)
+}
+
+// compiledPlan is a function with parsed arguments and registry settings.
+type compiledPlan struct {
+	// version of the registry when plan compiled.
+	version     uint64
+	withContext bool
+	conversion  bool
+	f           Func
+	args        []argPlan
+	middleware  []Middleware
+}
+
+// argPlan is an argument with parsed options.
+type argPlan struct {
+	name      string
+	fallbacks []string
+	optional  bool
+	// defaultAt is index of the default option, -1 when there is no default.
+	defaultAt int
+	// literal is the value of the literal expression.
+	literal reflect.Value
+	// visit is the argument given to VisitOptions of the custom Option.
+	visit   string
+	options []optionPlan
+}
+
+// optionPlan is an option with its function.
+type optionPlan struct {
+	name string
+	args []string
+	// fn is nil when option is not found.
+	fn func(context.Context, []reflect.Value, ...string) ([]reflect.Value, error)
+}
+
+// compile resolves autowired arguments, parses options and finds option functions.
+func (r *Reg) compile(state *callState, name string, f Func, args []string) (*compiledPlan, error) {
+	if f.Auto {
+		var err error
+		if args, err = r.autowire(state, f.Fn.Type(), args); err != nil {
+			return nil, newCallError(StageResolve, name, "", err)
+		}
+	}
+
+	argPlans := make([]argPlan, 0, len(args))
 	for _, arg := range args {
-		argPure := strings.SplitN(arg, r.GetDelimeter(), 2)[0]
-		// parse argument options
-		if v, ok := r.args[argPure]; ok {
-			// do options
-			vChanged, err := r.VisitOptions(arg, v)
+		argPlan, err := r.compileArg(arg)
+		if err != nil {
+			stage := StageOption
+			if _, ok := err.(*SyntaxError); ok {
+				stage, argPlan.name = StageResolve, arg
+			}
+
+			return nil, newCallError(stage, name, argPlan.name, err)
+		}
+
+		argPlans = append(argPlans, argPlan)
+	}
+
+	return &compiledPlan{
+		withContext: state.withContext,
+		conversion:  r.isConversion(),
+		f:           f,
+		args:        argPlans,
+		middleware:  r.middlewareChain(name),
+	}, nil
+}
+
+// compileArg parses argument expression and finds option functions.
+//
+// Expressions are parsed for Options, other Option implementations get the argument
+// in VisitOptions after the name is split with their delimeter.
+func (r *Reg) compileArg(arg string) (argPlan, error) {
+	if _, ok := r.Option.(*Options); !ok {
+		return argPlan{
+			name:      strings.SplitN(arg, r.GetDelimeter(), 2)[0],
+			defaultAt: -1,
+			visit:     arg,
+		}, nil
+	}
+
+	expr, err := ParseArgExpr(arg)
+	if err != nil {
+		return argPlan{}, err
+	}
+
+	return r.compileExpr(expr)
+}
+
+// compileExpr finds option functions of the expression.
+func (r *Reg) compileExpr(expr *ArgExpr) (argPlan, error) {
+	if expr.Literal {
+		return argPlan{
+			name:    "=" + expr.Value,
+			literal: reflect.ValueOf(jsonValue(expr.Value)),
+		}, nil
+	}
+
+	p := argPlan{
+		name:      expr.Name,
+		fallbacks: expr.Fallbacks,
+		optional:  expr.Optional,
+		defaultAt: -1,
+	}
+
+	for i, option := range expr.Options {
+		// unknown option fails when it is reached, so errors of the options before it come first
+		fn, _ := r.GetOptionContext(option.Name)
+
+		if option.Name == "default" && p.defaultAt < 0 {
+			p.defaultAt = i
+		}
+
+		p.options = append(p.options, optionPlan{
+			name: option.Name,
+			args: option.Args,
+			fn:   fn,
+		})
+	}
+
+	return p, nil
+}
+
+// execute resolves arguments of the plan and calls the function.
+func (r *Reg) execute(state *callState, name string, c *compiledPlan) ([]reflect.Value, error) {
+	f := c.f
+
+	fnArgs := make([]reflect.Value, 0, len(c.args))
+	// get arguments
+	for _, arg := range c.args {
+		vChanged, stage, err := r.resolveArg(state, arg)
+		if err != nil {
+			return nil, newCallError(stage, name, arg.name, err)
+		}
+
+		fnArgs = append(fnArgs, vChanged...)
+	}
+
+	fnArgs, err := r.injectParams(state, f.Fn.Type(), fnArgs)
+	if err != nil {
+		return nil, newCallError(StageResolve, name, "", err)
+	}
+
+	if err := bindArgs(name, f.Fn.Type(), fnArgs, c.conversion); err != nil {
+		return nil, newCallError(StageTypeCheck, name, "", err)
+	}
+
+	// check context before calling function
+	if err := state.ctx.Err(); err != nil {
+		return nil, newCallError(StageInvoke, name, "", err)
+	}
+
+	// call function
+	returnV, err := invokeMiddleware(state.ctx, name, c.middleware, f.Fn, fnArgs, c.conversion)
+	if err != nil {
+		var errBind *bindError
+		if errors.As(err, &errBind) {
+			return nil, newCallError(StageTypeCheck, name, "", errBind.err)
+		}
+
+		return nil, newCallError(StageInvoke, name, "", err)
+	}
+
+	return returnV, nil
+}
+
+// resolveArg resolves argument of the plan and applies its options.
+//
+// Fallbacks are tried in order when argument is not found. Missing arguments with default
+// option skip options before default and missing optional arguments are nil.
+func (r *Reg) resolveArg(state *callState, arg argPlan) ([]reflect.Value, Stage, error) {
+	if arg.literal.IsValid() {
+		return []reflect.Value{arg.literal}, "", nil
+	}
+
+	v, err := r.resolveArgument(state, arg.name)
+
+	if arg.visit != "" {
+		if err != nil {
+			return nil, StageResolve, err
+		}
+
+		values, err := r.visitOptions(state.ctx, arg.visit, v)
+		if err != nil {
+			return nil, StageOption, err
+		}
+
+		return values, "", nil
+	}
+
+	for _, name := range arg.fallbacks {
+		if err != ErrArgumentNotFound {
+			break
+		}
+
+		v, err = r.resolveArgument(state, name)
+	}
+
+	options := arg.options
+
+	if err == ErrArgumentNotFound {
+		switch {
+		case arg.defaultAt >= 0:
+			options, err = options[arg.defaultAt:], nil
+		case arg.optional:
+			return []reflect.Value{{}}, "", nil
+		}
+	}
+
+	if err != nil {
+		return nil, StageResolve, err
+	}
+
+	values, err := applyOptions(state.ctx, v, options)
+	if err != nil {
+		return nil, StageOption, err
+	}
+
+	return values, "", nil
+}
+
+// visitOptions applies options of the arg with the custom Option.
+func (r *Reg) visitOptions(ctx context.Context, arg string, v any) ([]reflect.Value, error) {
+	if o, ok := r.Option.(OptionContext); ok {
+		return o.VisitOptionsContext(ctx, arg, v)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return r.Option.VisitOptions(arg, v)
+}
+
+// applyOptions applies options to the value in order.
+//
+// ctx checked before each option, so chain stops when ctx is done.
+func applyOptions(ctx context.Context, v any, options []optionPlan) ([]reflect.Value, error) {
+	var err error
+
+	values := []reflect.Value{reflect.ValueOf(v)}
+
+	for _, option := range options {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if option.fn == nil {
+			return nil, &OptionError{Option: option.name, Err: ErrOptionNotFound}
+		}
+
+		values, err = callOption(ctx, option.fn, values, option.args)
+		if err != nil {
+			return nil, &OptionError{Option: option.name, Err: err}
+		}
+
+		if values == nil {
+			break
+		}
+	}
+
+	return values, nil
+}
+
+// resolveArgument returns argument value, providers are called to get value.
+//
+// Singletons are resolved in the registry which has them, other providers in r.
+func (r *Reg) resolveArgument(state *callState, name string) (any, error) {
+	v, p, owner, ok := r.lookupArgument(name)
+	if !ok {
+		return nil, ErrArgumentNotFound
+	}
+
+	if p == nil {
+		return v, nil
+	}
+
+	if p.singleton {
+		return owner.resolveProvider(state, name, p)
+	}
+
+	return r.resolveProvider(state, name, p)
+}
+
+// invoke calls the function and recovers panic.
+func invoke(fn reflect.Value, args []reflect.Value) (ret []reflect.Value, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = newPanicError(rec)
+		}
+	}()
+
+	return fn.Call(args), nil
+}
+
+// injectParams places values of the parameters which are filled without arguments.
+//
+// Context parameters get ctx when calling with context and struct parameters with
+// `call` tags get injected struct.
+// Struct parameter is not injected when the next argument is assignable to it,
+// so an explicit argument of the struct is used as is.
+func (r *Reg) injectParams(state *callState, fnType reflect.Type, args []reflect.Value) ([]reflect.Value, error) {
+	numIn := fnType.NumIn()
+	if fnType.IsVariadic() {
+		numIn--
+	}
+
+	ret := make([]reflect.Value, 0, len(args)+1)
+	for i := 0; i < numIn; i++ {
+		paramType := fnType.In(i)
+
+		if state.withContext && paramType == contextType {
+			ret = append(ret, reflect.ValueOf(state.ctx))
+
+			continue
+		}
+
+		if isInjectable(paramType) && !isAssignable(args, paramType) {
+			v, err := r.newInjected(state, paramType)
 			if err != nil {
-				return nil, fmt.Errorf("failed VisitOption %w", err)
+				return nil, fmt.Errorf("parameter %d: %w", i, err)
 			}
 
-			fnArgs = append(fnArgs, vChanged...)
-		} else {
-			return nil, fmt.Errorf("argument %s not found", arg)
+			ret = append(ret, v)
+
+			continue
+		}
+
+		if len(args) == 0 {
+			break
 		}
+
+		ret = append(ret, args[0])
+		args = args[1:]
 	}
 
+	return append(ret, args...), nil
+}
+
+// isAssignable reports first of the args is assignable to t.
+func isAssignable(args []reflect.Value, t reflect.Type) bool {
+	if len(args) == 0 || !args[0].IsValid() {
+		return false
+	}
+
+	return args[0].Type().AssignableTo(t)
+}
+
+// bindArgs checks every argument with the function's parameter types.
+//
+// Invalid values replaced with zero value of the parameter and arguments converted
+// to the parameter type when conversion is enabled.
+func bindArgs(name string, fnType reflect.Type, args []reflect.Value, convert bool) error {
 	// check length is equal to function arguments
-	if f.Fn.Type().IsVariadic() {
-		if len(fnArgs) < f.Fn.Type().NumIn()-1 {
-			return nil, fmt.Errorf("not enough arguments")
+	if fnType.IsVariadic() {
+		if len(args) < fnType.NumIn()-1 {
+			return fmt.Errorf("%w: want at least %d, got %d", ErrArgCountMismatch, fnType.NumIn()-1, len(args))
 		}
 	} else {
-		if len(fnArgs) != f.Fn.Type().NumIn() {
-			return nil, fmt.Errorf("argument count mismatch")
-		}
-	}
-
-	// check last argument type with variadic
-	if f.Fn.Type().IsVariadic() {
-		fnArgType := f.Fn.Type().In(f.Fn.Type().NumIn() - 1).Elem()
-		for i := f.Fn.Type().NumIn() - 1; i < len(fnArgs); i++ {
-			if fnArgs[i].IsValid() {
-				argType := fnArgs[i].Type()
-				if !argType.AssignableTo(fnArgType) {
-					return nil, fmt.Errorf("variadic function: index %d argument %s type mismatch with function %s type", i, argType, fnArgType)
-				}
-			} else {
-				fnArgs[i] = reflect.Zero(fnArgType)
-			}
+		if len(args) != fnType.NumIn() {
+			return fmt.Errorf("%w: want %d, got %d", ErrArgCountMismatch, fnType.NumIn(), len(args))
 		}
-	} else if f.Fn.Type().NumIn() > 0 {
-		fnArgType := f.Fn.Type().In(f.Fn.Type().NumIn() - 1)
-		if fnArgs[len(fnArgs)-1].IsValid() {
-			argType := fnArgs[len(fnArgs)-1].Type()
-			if !argType.AssignableTo(fnArgType) {
-				return nil, fmt.Errorf("function: index %d argument %s type mismatch with function %s type", len(fnArgs)-1, argType, fnArgType)
+	}
+
+	for i, arg := range args {
+		paramType, variadic := parameterType(fnType, i)
+
+		// get concrete value of the interface
+		if arg.Kind() == reflect.Interface {
+			arg = arg.Elem()
+		}
+
+		if !arg.IsValid() {
+			args[i] = reflect.Zero(paramType)
+
+			continue
+		}
+
+		if v, ok, err := parseRaw(arg, paramType); ok {
+			if err != nil {
+				return fmt.Errorf("index %d value %s cannot be parsed to %s: %w", i, rawString(arg), paramType, err)
 			}
-		} else {
-			fnArgs[len(fnArgs)-1] = reflect.Zero(fnArgType)
+
+			args[i] = v
+
+			continue
+		}
+
+		if arg.Type().AssignableTo(paramType) {
+			args[i] = arg
+
+			continue
+		}
+
+		if convert && canConvert(arg, paramType) {
+			args[i] = arg.Convert(paramType)
+
+			continue
+		}
+
+		return &TypeError{
+			Function: name,
+			Index:    i,
+			Variadic: variadic,
+			Expected: paramType,
+			Actual:   arg.Type(),
 		}
 	}
 
-	// call function
-	returnV := f.Fn.Call(fnArgs)
+	return nil
+}
 
-	// convert return values to []any
-	returns := make([]any, len(returnV))
-	for i, v := range returnV {
-		returns[i] = v.Interface()
+// parameterType returns type of the i'th parameter, variadic parameters return element type.
+func parameterType(fnType reflect.Type, i int) (reflect.Type, bool) {
+	if fnType.IsVariadic() && i >= fnType.NumIn()-1 {
+		return fnType.In(fnType.NumIn() - 1).Elem(), true
+	}
+
+	return fnType.In(i), false
+}
+
+// canConvert reports value can be converted to t.
+//
+// Integer to string conversion is not allowed, it produces rune value.
+func canConvert(v reflect.Value, t reflect.Type) bool {
+	if t.Kind() == reflect.String {
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return false
+		}
 	}
 
-	return returns, nil
+	return v.CanConvert(t)
 }
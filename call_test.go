@@ -1,8 +1,12 @@
 package call
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestReg_CallWithArgs(t *testing.T) {
@@ -19,17 +23,17 @@ func TestReg_CallWithArgs(t *testing.T) {
 		wantErrStr string
 	}{
 		{
-			name: "function test not found",
+			name: "resolve function test: function not found",
 			args: args{
 				name: "test",
 				args: []string{"test-1", "test-2"},
 			},
 			want:       nil,
 			wantErr:    true,
-			wantErrStr: "function test not found",
+			wantErrStr: "resolve function test: function not found",
 		},
 		{
-			name: "argument test-1 not found",
+			name: "resolve function test argument test-1: argument not found",
 			modify: func(r *Reg) {
 				r.AddFunction("test", func() {})
 			},
@@ -39,7 +43,7 @@ func TestReg_CallWithArgs(t *testing.T) {
 			},
 			want:       nil,
 			wantErr:    true,
-			wantErrStr: "argument test-1 not found",
+			wantErrStr: "resolve function test argument test-1: argument not found",
 		},
 		{
 			name: "optionX not found",
@@ -53,7 +57,7 @@ func TestReg_CallWithArgs(t *testing.T) {
 			},
 			want:       nil,
 			wantErr:    true,
-			wantErrStr: "failed VisitOption option optionX not found",
+			wantErrStr: "option function test argument test-1 option optionX: option not found",
 		},
 		{
 			name: "not enough arguments",
@@ -67,7 +71,7 @@ func TestReg_CallWithArgs(t *testing.T) {
 			},
 			want:       nil,
 			wantErr:    true,
-			wantErrStr: "not enough arguments",
+			wantErrStr: "typecheck function test: argument count mismatch: want at least 2, got 1",
 		},
 		{
 			name: "argument count mismatch",
@@ -81,7 +85,7 @@ func TestReg_CallWithArgs(t *testing.T) {
 			},
 			want:       nil,
 			wantErr:    true,
-			wantErrStr: "argument count mismatch",
+			wantErrStr: "typecheck function test: argument count mismatch: want 2, got 1",
 		},
 		{
 			name: "variadic argument type check",
@@ -95,7 +99,7 @@ func TestReg_CallWithArgs(t *testing.T) {
 			},
 			want:       nil,
 			wantErr:    true,
-			wantErrStr: "variadic function: index 3 argument string type mismatch with function float64 type",
+			wantErrStr: "typecheck function test: index 3 argument string type mismatch with variadic function float64 type",
 		},
 		{
 			name: "argument type check",
@@ -109,7 +113,35 @@ func TestReg_CallWithArgs(t *testing.T) {
 			},
 			want:       nil,
 			wantErr:    true,
-			wantErrStr: "function: index 2 argument int type mismatch with function string type",
+			wantErrStr: "typecheck function test: index 2 argument int type mismatch with function string type",
+		},
+		{
+			name: "first argument type check",
+			modify: func(r *Reg) {
+				r.AddFunction("test", func(int, string) {})
+				r.AddArgument("test-1", "test-1")
+			},
+			args: args{
+				name: "test",
+				args: []string{"test-1", "test-1"},
+			},
+			want:       nil,
+			wantErr:    true,
+			wantErrStr: "typecheck function test: index 0 argument string type mismatch with function int type",
+		},
+		{
+			name: "interface value",
+			modify: func(r *Reg) {
+				r.AddFunction("test", func(v string, x int) string { return v })
+				r.AddArgument("test-1", []any{"test-1", 2})
+			},
+			args: args{
+				name: "test",
+				args: []string{"test-1:index=0,1"},
+			},
+			want: []any{
+				"test-1",
+			},
 		},
 		{
 			name: "argument nil",
@@ -176,3 +208,336 @@ func TestReg_CallWithArgs(t *testing.T) {
 		})
 	}
 }
+
+func TestReg_CallWithDefaults(t *testing.T) {
+	reg := NewReg().
+		AddArgument("replica", "replica").
+		AddArgument("nilValue", nil).
+		AddArgument("config", map[string]any{"port": 8080}).
+		AddFunction("str", func(v string) string { return v }).
+		AddFunction("duration", func(v time.Duration) time.Duration { return v }).
+		AddFunction("int", func(v int) int { return v }).
+		AddFunction("any", func(v any) any { return v })
+
+	tests := []struct {
+		fn         string
+		arg        string
+		want       any
+		wantErrStr string
+	}{
+		{fn: "str", arg: "primary|replica", want: "replica"},
+		{fn: "str", arg: "primary|secondary|replica", want: "replica"},
+		{fn: "str", arg: "primary?", want: ""},
+		{fn: "str", arg: "primary|replica?", want: "replica"},
+		{fn: "str", arg: "primary?:index=0", want: ""},
+		{fn: "duration", arg: "timeout:default=30s", want: 30 * time.Second},
+		{fn: "duration", arg: "nilValue:default=1m", want: time.Minute},
+		{fn: "int", arg: "config:index=port;default=80", want: 8080},
+		{fn: "int", arg: "config:index=host;default=80", want: 80},
+		{fn: "int", arg: "missing:index=port;default=80", want: 80},
+		{fn: "str", arg: "missing:default=a,b", want: "a,b"},
+		{fn: "any", arg: "missing:default=x", want: "x"},
+		{
+			fn:         "str",
+			arg:        "primary|secondary",
+			wantErrStr: "resolve function str argument primary: argument not found",
+		},
+		{
+			fn:         "int",
+			arg:        "missing:default=x",
+			wantErrStr: `typecheck function int: index 0 value "x" cannot be parsed to int: strconv.ParseInt: parsing "x": invalid syntax`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			got, err := reg.CallWithArgs(tt.fn, tt.arg)
+			if err != nil {
+				if err.Error() != tt.wantErrStr {
+					t.Errorf("Reg.CallWithArgs() error = %v, wantErrStr %v", err, tt.wantErrStr)
+				}
+				return
+			}
+			if tt.wantErrStr != "" {
+				t.Fatalf("Reg.CallWithArgs() error = nil, wantErrStr %v", tt.wantErrStr)
+			}
+			if !reflect.DeepEqual(got[0], tt.want) {
+				t.Errorf("Reg.CallWithArgs() = %v, want %v", got[0], tt.want)
+			}
+		})
+	}
+}
+
+func TestReg_CallWithLiterals(t *testing.T) {
+	type point struct {
+		X, Y int
+	}
+
+	reg := NewReg().
+		AddFunction("int", func(v int8) int8 { return v }).
+		AddFunction("str", func(v string) string { return v }).
+		AddFunction("bool", func(v bool) bool { return v }).
+		AddFunction("ints", func(v []int) []int { return v }).
+		AddFunction("point", func(v point) point { return v }).
+		AddFunction("duration", func(v time.Duration) time.Duration { return v }).
+		AddFunction("any", func(v any) any { return v }).
+		AddFunction("sum", func(v ...int) int {
+			total := 0
+			for _, n := range v {
+				total += n
+			}
+
+			return total
+		})
+
+	tests := []struct {
+		fn         string
+		args       []string
+		want       any
+		wantErrStr string
+	}{
+		{fn: "int", args: []string{"=42"}, want: int8(42)},
+		{fn: "str", args: []string{`="hello"`}, want: "hello"},
+		{fn: "bool", args: []string{"=true"}, want: true},
+		{fn: "ints", args: []string{"=[1,2,3]"}, want: []int{1, 2, 3}},
+		{fn: "point", args: []string{`={"X":1,"Y":2}`}, want: point{X: 1, Y: 2}},
+		{fn: "duration", args: []string{`="30s"`}, want: 30 * time.Second},
+		{fn: "duration", args: []string{"=1000"}, want: time.Microsecond},
+		{fn: "any", args: []string{"=[1]"}, want: []any{1.0}},
+		{fn: "sum", args: []string{"=1", "=2"}, want: 3},
+		{
+			fn:         "int",
+			args:       []string{"=300"},
+			wantErrStr: "typecheck function int: index 0 value 300 cannot be parsed to int8: json: cannot unmarshal number 300 into Go value of type int8",
+		},
+		{
+			fn:         "int",
+			args:       []string{"=x"},
+			wantErrStr: `resolve function int argument =x: syntax error at column 2 near "x": invalid literal: invalid character 'x' looking for beginning of value`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.args[0], func(t *testing.T) {
+			got, err := reg.CallWithArgs(tt.fn, tt.args...)
+			if err != nil {
+				if err.Error() != tt.wantErrStr {
+					t.Errorf("Reg.CallWithArgs() error = %v, wantErrStr %v", err, tt.wantErrStr)
+				}
+				return
+			}
+			if tt.wantErrStr != "" {
+				t.Fatalf("Reg.CallWithArgs() error = nil, wantErrStr %v", tt.wantErrStr)
+			}
+			if !reflect.DeepEqual(got[0], tt.want) {
+				t.Errorf("Reg.CallWithArgs() = %v, want %v", got[0], tt.want)
+			}
+		})
+	}
+
+	t.Run("registered function", func(t *testing.T) {
+		reg.AddFunction("answer", func(v int, s string) string { return fmt.Sprint(s, v) }, "=42", `="answer "`)
+
+		got, err := reg.Call("answer")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got[0] != "answer 42" {
+			t.Errorf("Reg.Call() = %v, want %v", got[0], "answer 42")
+		}
+
+		if names := reg.GetArgumentNames(); len(names) != 0 {
+			t.Errorf("Reg.GetArgumentNames() = %v, want empty", names)
+		}
+	})
+}
+
+func TestReg_CallWithArgsContext(t *testing.T) {
+	type ctxKey struct{}
+
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	optionCtx, optionCancel := context.WithCancel(context.Background())
+	defer optionCancel()
+
+	type args struct {
+		ctx  context.Context
+		name string
+		args []string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		modify  func(*Reg)
+		want    []any
+		wantErr error
+	}{
+		{
+			name: "context injected",
+			modify: func(r *Reg) {
+				r.AddFunction("test", func(ctx context.Context, v string) string {
+					return ctx.Value(ctxKey{}).(string) + v
+				})
+				r.AddArgument("test-1", "-test-1")
+			},
+			args: args{
+				ctx:  context.WithValue(context.Background(), ctxKey{}, "ctx"),
+				name: "test",
+				args: []string{"test-1"},
+			},
+			want: []any{"ctx-test-1"},
+		},
+		{
+			name: "context injected middle and variadic",
+			modify: func(r *Reg) {
+				r.AddFunction("test", func(v string, ctx context.Context, x ...int) int {
+					return len(v) + len(x)
+				})
+				r.AddArgument("test-1", "test-1").AddArgument("number", 1)
+			},
+			args: args{
+				ctx:  context.Background(),
+				name: "test",
+				args: []string{"test-1", "number", "number"},
+			},
+			want: []any{8},
+		},
+		{
+			name: "canceled context",
+			modify: func(r *Reg) {
+				r.AddFunction("test", func(context.Context) {})
+			},
+			args: args{
+				ctx:  canceledCtx,
+				name: "test",
+			},
+			wantErr: context.Canceled,
+		},
+		{
+			name: "canceled in option",
+			modify: func(r *Reg) {
+				r.AddOptionContext("cancel", func(_ context.Context, v []reflect.Value, _ ...string) ([]reflect.Value, error) {
+					optionCancel()
+
+					return v, nil
+				})
+				r.AddFunction("test", func(context.Context, string) {})
+				r.AddArgument("test-1", "test-1")
+			},
+			args: args{
+				ctx:  optionCtx,
+				name: "test",
+				args: []string{"test-1:cancel;cancel"},
+			},
+			wantErr: context.Canceled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewReg()
+			if tt.modify != nil {
+				tt.modify(r)
+			}
+
+			got, err := r.CallWithArgsContext(tt.args.ctx, tt.args.name, tt.args.args...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Reg.CallWithArgsContext() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Reg.CallWithArgsContext() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReg_SetConversion(t *testing.T) {
+	type myString string
+
+	tests := []struct {
+		name       string
+		conversion bool
+		fn         any
+		arg        any
+		want       []any
+		wantErr    *TypeError
+	}{
+		{
+			name:       "int to int64",
+			conversion: true,
+			fn:         func(v int64) int64 { return v },
+			arg:        5,
+			want:       []any{int64(5)},
+		},
+		{
+			name:       "string to []byte",
+			conversion: true,
+			fn:         func(v []byte) []byte { return v },
+			arg:        "test",
+			want:       []any{[]byte("test")},
+		},
+		{
+			name:       "named type to underlying",
+			conversion: true,
+			fn:         func(v string) string { return v },
+			arg:        myString("test"),
+			want:       []any{"test"},
+		},
+		{
+			name:       "variadic conversion",
+			conversion: true,
+			fn:         func(v ...float64) float64 { return v[0] },
+			arg:        5,
+			want:       []any{float64(5)},
+		},
+		{
+			name:       "int to string not allowed",
+			conversion: true,
+			fn:         func(v string) string { return v },
+			arg:        65,
+			wantErr: &TypeError{
+				Function: "test",
+				Index:    0,
+				Expected: reflect.TypeOf(""),
+				Actual:   reflect.TypeOf(0),
+			},
+		},
+		{
+			name: "disabled conversion",
+			fn:   func(v int64) int64 { return v },
+			arg:  5,
+			wantErr: &TypeError{
+				Function: "test",
+				Index:    0,
+				Expected: reflect.TypeOf(int64(0)),
+				Actual:   reflect.TypeOf(0),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewReg().SetConversion(tt.conversion).
+				AddFunction("test", tt.fn).
+				AddArgument("arg", tt.arg)
+
+			got, err := r.CallWithArgs("test", "arg")
+			if tt.wantErr != nil {
+				var typeErr *TypeError
+				if !errors.As(err, &typeErr) {
+					t.Fatalf("Reg.CallWithArgs() error = %v, want TypeError", err)
+				}
+				if !reflect.DeepEqual(typeErr, tt.wantErr) {
+					t.Errorf("Reg.CallWithArgs() error = %#v, want %#v", typeErr, tt.wantErr)
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("Reg.CallWithArgs() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Reg.CallWithArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
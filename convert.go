@@ -0,0 +1,155 @@
+package call
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	textValueType       = reflect.TypeOf(textValue(""))
+	jsonValueType       = reflect.TypeOf(jsonValue(""))
+)
+
+// textValue is parsed to the parameter type when binding arguments, like default values.
+type textValue string
+
+// jsonValue is a literal which is decoded to the parameter type when binding arguments.
+type jsonValue string
+
+// parseRaw parses textValue and jsonValue to t, ok is false for other values.
+func parseRaw(v reflect.Value, t reflect.Type) (_ reflect.Value, ok bool, _ error) {
+	switch v.Type() {
+	case textValueType:
+		parsed, err := parseText(v.String(), t)
+
+		return parsed, true, err
+	case jsonValueType:
+		parsed, err := parseJSON(v.String(), t)
+
+		return parsed, true, err
+	}
+
+	return reflect.Value{}, false, nil
+}
+
+// rawString returns textValue quoted and jsonValue as it is.
+func rawString(v reflect.Value) string {
+	if v.Type() == textValueType {
+		return strconv.Quote(v.String())
+	}
+
+	return v.String()
+}
+
+// parseJSON decodes JSON to t, JSON strings are parsed like text values when decoding fails.
+//
+// `"30s"` is decoded to time.Duration with this way.
+func parseJSON(s string, t reflect.Type) (reflect.Value, error) {
+	v := reflect.New(t)
+
+	err := json.Unmarshal([]byte(s), v.Interface())
+	if err == nil {
+		return v.Elem(), nil
+	}
+
+	var str string
+	if json.Unmarshal([]byte(s), &str) == nil {
+		if parsed, errParse := parseText(str, t); errParse == nil {
+			return parsed, nil
+		}
+	}
+
+	return reflect.Value{}, err
+}
+
+// parseText parses text value to t, interface types get the string.
+func parseText(s string, t reflect.Type) (reflect.Value, error) {
+	if t.Kind() == reflect.Interface && reflect.TypeOf(s).AssignableTo(t) {
+		return reflect.ValueOf(s), nil
+	}
+
+	return parseValue(s, t)
+}
+
+// parseValue parses string to the t type.
+//
+// Supports encoding.TextUnmarshaler, time.Duration, strings, booleans and numbers.
+func parseValue(s string, t reflect.Type) (reflect.Value, error) {
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		v := reflect.New(t)
+		if err := v.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			return reflect.Value{}, err
+		}
+
+		return v.Elem(), nil
+	}
+
+	v := reflect.New(t).Elem()
+
+	if t == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		v.SetInt(int64(d))
+
+		return v, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, t.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(s, 10, t.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, t.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		v.SetFloat(f)
+	default:
+		return reflect.Value{}, fmt.Errorf("cannot parse string to %s", t)
+	}
+
+	return v, nil
+}
+
+// mapKey converts string to the key type of the map.
+func mapKey(keyType reflect.Type, s string) (reflect.Value, error) {
+	if keyType.Kind() == reflect.String {
+		return reflect.ValueOf(s).Convert(keyType), nil
+	}
+
+	if keyType.Kind() == reflect.Interface && reflect.TypeOf(s).AssignableTo(keyType) {
+		return reflect.ValueOf(s), nil
+	}
+
+	return parseValue(s, keyType)
+}
@@ -0,0 +1,59 @@
+package call
+
+import (
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseValue(t *testing.T) {
+	type myString string
+
+	tests := []struct {
+		name       string
+		s          string
+		t          reflect.Type
+		want       any
+		wantErrStr string
+	}{
+		{name: "string", s: "test", t: reflect.TypeOf(""), want: "test"},
+		{name: "named string", s: "test", t: reflect.TypeOf(myString("")), want: myString("test")},
+		{name: "bool", s: "true", t: reflect.TypeOf(false), want: true},
+		{name: "int8", s: "-12", t: reflect.TypeOf(int8(0)), want: int8(-12)},
+		{name: "uint", s: "12", t: reflect.TypeOf(uint(0)), want: uint(12)},
+		{name: "float", s: "1.5", t: reflect.TypeOf(float32(0)), want: float32(1.5)},
+		{name: "duration", s: "1m", t: reflect.TypeOf(time.Duration(0)), want: time.Minute},
+		{name: "text unmarshaler", s: "127.0.0.1", t: reflect.TypeOf(net.IP{}), want: net.ParseIP("127.0.0.1")},
+		{
+			name:       "int overflow",
+			s:          "300",
+			t:          reflect.TypeOf(int8(0)),
+			wantErrStr: `strconv.ParseInt: parsing "300": value out of range`,
+		},
+		{
+			name:       "unsupported",
+			s:          "test",
+			t:          reflect.TypeOf([]string{}),
+			wantErrStr: "cannot parse string to []string",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseValue(tt.s, tt.t)
+			if (err != nil) != (tt.wantErrStr != "") {
+				t.Fatalf("parseValue() error = %v, wantErrStr %v", err, tt.wantErrStr)
+			}
+			if err != nil {
+				if err.Error() != tt.wantErrStr {
+					t.Errorf("parseValue() error = %v, wantErrStr %v", err, tt.wantErrStr)
+				}
+
+				return
+			}
+			if !reflect.DeepEqual(got.Interface(), tt.want) {
+				t.Errorf("parseValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
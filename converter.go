@@ -0,0 +1,236 @@
+package call
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Converter converts value to another type, args are the rest of the option arguments.
+//
+// `since:parse=time,RFC3339` calls "time" converter with "RFC3339" argument.
+type Converter func(v reflect.Value, args ...string) (reflect.Value, error)
+
+// converters is the named converters of the registry, shared with scopes like options.
+type converters struct {
+	m     map[string]Converter
+	mutex sync.RWMutex
+}
+
+var timeLayouts = map[string]string{
+	"ANSIC":       time.ANSIC,
+	"UnixDate":    time.UnixDate,
+	"RubyDate":    time.RubyDate,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"Kitchen":     time.Kitchen,
+	"DateTime":    time.DateTime,
+	"DateOnly":    time.DateOnly,
+	"TimeOnly":    time.TimeOnly,
+}
+
+func newConverters() *converters {
+	return &converters{
+		m: map[string]Converter{
+			"string":   TypeConverter[string](),
+			"bytes":    TypeConverter[[]byte](),
+			"bool":     TypeConverter[bool](),
+			"int":      TypeConverter[int](),
+			"int8":     TypeConverter[int8](),
+			"int16":    TypeConverter[int16](),
+			"int32":    TypeConverter[int32](),
+			"int64":    TypeConverter[int64](),
+			"uint":     TypeConverter[uint](),
+			"uint8":    TypeConverter[uint8](),
+			"uint16":   TypeConverter[uint16](),
+			"uint32":   TypeConverter[uint32](),
+			"uint64":   TypeConverter[uint64](),
+			"float32":  TypeConverter[float32](),
+			"float64":  TypeConverter[float64](),
+			"duration": TypeConverter[time.Duration](),
+			"time":     convertTime,
+		},
+	}
+}
+
+func (c *converters) get(name string) (Converter, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	fn, ok := c.m[name]
+
+	return fn, ok
+}
+
+func (c *converters) add(name string, fn Converter) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.m[name] = fn
+}
+
+// AddConverter adds named converter for "as" and "parse" options, scopes share converters.
+func (r *Reg) AddConverter(name string, fn Converter) *Reg {
+	r.converters.add(name, fn)
+
+	return r
+}
+
+// GetConverter returns converter with name.
+func (r *Reg) GetConverter(name string) (Converter, bool) {
+	return r.converters.get(name)
+}
+
+// TypeConverter returns converter to T.
+//
+// Strings are parsed like struct tag defaults, encoding.TextUnmarshaler types are supported.
+// Other values are converted with Go conversion rules.
+func TypeConverter[T any]() Converter {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+
+	return func(v reflect.Value, _ ...string) (reflect.Value, error) {
+		return convertValue(v, t)
+	}
+}
+
+// convertValue converts value to t.
+func convertValue(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+	v = elem(v)
+	if !v.IsValid() {
+		return reflect.Zero(t), nil
+	}
+
+	if v.Type() == t {
+		return v, nil
+	}
+
+	if v.Kind() == reflect.String && t.Kind() != reflect.String {
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 && !reflect.PointerTo(t).Implements(textUnmarshalerType) {
+			return v.Convert(t), nil
+		}
+
+		return parseValue(v.String(), t)
+	}
+
+	if canConvert(v, t) {
+		return v.Convert(t), nil
+	}
+
+	return reflect.Value{}, fmt.Errorf("cannot convert %s to %s", v.Type(), t)
+}
+
+// convertTime parses time with layout name like RFC3339 or layout itself, RFC3339 is default.
+func convertTime(v reflect.Value, args ...string) (reflect.Value, error) {
+	v = elem(v)
+	if v.IsValid() && v.Type() == reflect.TypeOf(time.Time{}) {
+		return v, nil
+	}
+
+	if !v.IsValid() || v.Kind() != reflect.String {
+		return reflect.Value{}, fmt.Errorf("cannot convert %s to time", valueType(v))
+	}
+
+	layout := time.RFC3339
+	if len(args) > 0 {
+		layout = args[0]
+		if l, ok := timeLayouts[layout]; ok {
+			layout = l
+		}
+	}
+
+	t, err := time.Parse(layout, v.String())
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	return reflect.ValueOf(t), nil
+}
+
+// OptionAs converts values with the named converter like `as=int`.
+func (r *Reg) OptionAs(v []reflect.Value, args ...string) ([]reflect.Value, error) {
+	return r.convertOption(v, args, false)
+}
+
+// OptionParse parses string form of the values with the named converter like `parse=time,RFC3339`.
+//
+// []byte and encoding.TextMarshaler values are used as string.
+func (r *Reg) OptionParse(v []reflect.Value, args ...string) ([]reflect.Value, error) {
+	return r.convertOption(v, args, true)
+}
+
+func (r *Reg) convertOption(v []reflect.Value, args []string, parse bool) ([]reflect.Value, error) {
+	if len(v) == 0 {
+		return nil, fmt.Errorf("no value")
+	}
+
+	if len(args) == 0 {
+		return nil, fmt.Errorf("converter is empty")
+	}
+
+	fn, ok := r.GetConverter(args[0])
+	if !ok {
+		return nil, fmt.Errorf("converter %s not found", args[0])
+	}
+
+	retV := make([]reflect.Value, 0, len(v))
+	for _, value := range v {
+		if parse {
+			s, err := stringValue(value)
+			if err != nil {
+				return nil, err
+			}
+
+			value = reflect.ValueOf(s)
+		}
+
+		converted, err := fn(value, args[1:]...)
+		if err != nil {
+			return nil, fmt.Errorf("convert %s; %w", args[0], err)
+		}
+
+		retV = append(retV, converted)
+	}
+
+	return retV, nil
+}
+
+// stringValue returns string form of strings, []byte and encoding.TextMarshaler values.
+func stringValue(v reflect.Value) (string, error) {
+	v = elem(v)
+
+	if v.IsValid() {
+		if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+			text, err := m.MarshalText()
+			if err != nil {
+				return "", err
+			}
+
+			return string(text), nil
+		}
+
+		switch {
+		case v.Kind() == reflect.String:
+			return v.String(), nil
+		case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+			return string(v.Bytes()), nil
+		}
+	}
+
+	return "", fmt.Errorf("cannot parse %s, not a string", valueType(v))
+}
+
+// valueType returns type name of the value, invalid value is nil.
+func valueType(v reflect.Value) string {
+	if !v.IsValid() {
+		return "nil"
+	}
+
+	return v.Type().String()
+}
@@ -0,0 +1,200 @@
+package call
+
+import (
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReg_OptionAs(t *testing.T) {
+	reg := NewReg()
+
+	tests := []struct {
+		name       string
+		v          []reflect.Value
+		args       []string
+		want       []any
+		wantErrStr string
+	}{
+		{
+			name: "string to int",
+			v:    []reflect.Value{reflect.ValueOf("8080")},
+			args: []string{"int"},
+			want: []any{8080},
+		},
+		{
+			name: "number conversion",
+			v:    []reflect.Value{reflect.ValueOf(1.5), reflect.ValueOf(2)},
+			args: []string{"int64"},
+			want: []any{int64(1), int64(2)},
+		},
+		{
+			name: "interface value",
+			v:    []reflect.Value{reflect.ValueOf([]any{"true"}).Index(0)},
+			args: []string{"bool"},
+			want: []any{true},
+		},
+		{
+			name: "duration",
+			v:    []reflect.Value{reflect.ValueOf("30s")},
+			args: []string{"duration"},
+			want: []any{30 * time.Second},
+		},
+		{
+			name: "bytes",
+			v:    []reflect.Value{reflect.ValueOf("abc")},
+			args: []string{"bytes"},
+			want: []any{[]byte("abc")},
+		},
+		{
+			name:       "int to string",
+			v:          []reflect.Value{reflect.ValueOf(65)},
+			args:       []string{"string"},
+			wantErrStr: "convert string; cannot convert int to string",
+		},
+		{
+			name:       "wrong value",
+			v:          []reflect.Value{reflect.ValueOf("abc")},
+			args:       []string{"uint"},
+			wantErrStr: `convert uint; strconv.ParseUint: parsing "abc": invalid syntax`,
+		},
+		{
+			name:       "converter not found",
+			v:          []reflect.Value{reflect.ValueOf("abc")},
+			args:       []string{"uuid"},
+			wantErrStr: "converter uuid not found",
+		},
+		{
+			name:       "converter is empty",
+			v:          []reflect.Value{reflect.ValueOf("abc")},
+			wantErrStr: "converter is empty",
+		},
+		{
+			name:       "no value",
+			args:       []string{"int"},
+			wantErrStr: "no value",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := reg.OptionAs(tt.v, tt.args...)
+			if err != nil {
+				if err.Error() != tt.wantErrStr {
+					t.Errorf("OptionAs() error = %v, wantErrStr %v", err, tt.wantErrStr)
+				}
+				return
+			}
+			if tt.wantErrStr != "" {
+				t.Fatalf("OptionAs() error = nil, wantErrStr %v", tt.wantErrStr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("OptionAs() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !reflect.DeepEqual(got[i].Interface(), tt.want[i]) {
+					t.Errorf("OptionAs() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestReg_OptionParse(t *testing.T) {
+	reg := NewReg()
+
+	tests := []struct {
+		name       string
+		v          reflect.Value
+		args       []string
+		want       any
+		wantErrStr string
+	}{
+		{
+			name: "time default layout",
+			v:    reflect.ValueOf("2023-01-02T03:04:05Z"),
+			args: []string{"time"},
+			want: time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+		{
+			name: "time layout name",
+			v:    reflect.ValueOf("2023-01-02"),
+			args: []string{"time", "DateOnly"},
+			want: time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "time layout",
+			v:    reflect.ValueOf("02/01/2023"),
+			args: []string{"time", "02/01/2006"},
+			want: time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "bytes",
+			v:    reflect.ValueOf([]byte("12")),
+			args: []string{"uint8"},
+			want: uint8(12),
+		},
+		{
+			name: "text marshaler",
+			v:    reflect.ValueOf(net.ParseIP("127.0.0.1")),
+			args: []string{"string"},
+			want: "127.0.0.1",
+		},
+		{
+			name:       "not a string",
+			v:          reflect.ValueOf(12),
+			args:       []string{"int"},
+			wantErrStr: "cannot parse int, not a string",
+		},
+		{
+			name:       "wrong time",
+			v:          reflect.ValueOf("yesterday"),
+			args:       []string{"time", "DateOnly"},
+			wantErrStr: `convert time; parsing time "yesterday" as "2006-01-02": cannot parse "yesterday" as "2006"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := reg.OptionParse([]reflect.Value{tt.v}, tt.args...)
+			if err != nil {
+				if err.Error() != tt.wantErrStr {
+					t.Errorf("OptionParse() error = %v, wantErrStr %v", err, tt.wantErrStr)
+				}
+				return
+			}
+			if tt.wantErrStr != "" {
+				t.Fatalf("OptionParse() error = nil, wantErrStr %v", tt.wantErrStr)
+			}
+			if !reflect.DeepEqual(got[0].Interface(), tt.want) {
+				t.Errorf("OptionParse() = %v, want %v", got[0], tt.want)
+			}
+		})
+	}
+}
+
+func TestReg_AddConverter(t *testing.T) {
+	reg := NewReg().
+		AddConverter("upper", func(v reflect.Value, _ ...string) (reflect.Value, error) {
+			return reflect.ValueOf(strings.ToUpper(v.String())), nil
+		}).
+		AddConverter("ip", TypeConverter[net.IP]()).
+		AddArgument("name", "call").
+		AddArgument("host", "10.0.0.1").
+		AddArgument("port", "8080").
+		AddArgument("since", "2023-01-02").
+		AddFunction("server", func(name string, ip net.IP, port int, since time.Time) string {
+			return name + " " + ip.String() + " " + since.Format(time.DateOnly)
+		}, "name:as=upper", "host:parse=ip", "port:as=int", "since:parse=time,DateOnly")
+
+	scope := reg.NewScope()
+
+	returns, err := scope.Call("server")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "CALL 10.0.0.1 2023-01-02"; returns[0] != want {
+		t.Errorf("server = %v, want %v", returns[0], want)
+	}
+}
@@ -0,0 +1,161 @@
+package call
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"strings"
+)
+
+var (
+	ErrFunctionNotFound = errors.New("function not found")
+	ErrArgumentNotFound = errors.New("argument not found")
+	ErrOptionNotFound   = errors.New("option not found")
+	ErrArgCountMismatch = errors.New("argument count mismatch")
+	// ErrCircularDependency returned when providers depend on each other.
+	ErrCircularDependency = errors.New("circular dependency")
+	// ErrAmbiguousArgument returned when autowire finds more than one argument for a parameter.
+	ErrAmbiguousArgument = errors.New("ambiguous argument")
+)
+
+// Stage is the step of the call where error occurred.
+type Stage string
+
+const (
+	// StageResolve is finding function and arguments in registry.
+	StageResolve Stage = "resolve"
+	// StageOption is applying options to the arguments.
+	StageOption Stage = "option"
+	// StageTypeCheck is checking arguments with function parameters.
+	StageTypeCheck Stage = "typecheck"
+	// StageInvoke is calling the function.
+	StageInvoke Stage = "invoke"
+)
+
+// CallError is returned from calls, use errors.Is with sentinel errors or errors.As to get details.
+type CallError struct {
+	Function string
+	Argument string
+	Option   string
+	Stage    Stage
+	Err      error
+	// Stack is stack trace of the panic, empty when error is not a panic.
+	Stack []byte
+}
+
+func newCallError(stage Stage, function, argument string, err error) *CallError {
+	e := &CallError{
+		Function: function,
+		Argument: argument,
+		Stage:    stage,
+		Err:      err,
+	}
+
+	if optionErr, ok := err.(*OptionError); ok {
+		e.Option = optionErr.Option
+		e.Err = optionErr.Err
+	}
+
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) {
+		e.Stack = panicErr.Stack
+	}
+
+	return e
+}
+
+func (e *CallError) Error() string {
+	var b strings.Builder
+
+	b.WriteString(string(e.Stage))
+	b.WriteString(" function ")
+	b.WriteString(e.Function)
+
+	if e.Argument != "" {
+		b.WriteString(" argument ")
+		b.WriteString(e.Argument)
+	}
+
+	if e.Option != "" {
+		b.WriteString(" option ")
+		b.WriteString(e.Option)
+	}
+
+	b.WriteString(": ")
+	b.WriteString(e.Err.Error())
+
+	return b.String()
+}
+
+func (e *CallError) Unwrap() error {
+	return e.Err
+}
+
+// OptionError is returned when option is not found or option function fails.
+type OptionError struct {
+	Option string
+	Err    error
+}
+
+func (e *OptionError) Error() string {
+	return fmt.Sprintf("%s; %v", e.Option, e.Err)
+}
+
+func (e *OptionError) Unwrap() error {
+	return e.Err
+}
+
+// PanicError holds recovered panic value with stack trace.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func newPanicError(v any) *PanicError {
+	return &PanicError{
+		Value: v,
+		Stack: debug.Stack(),
+	}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// TypeError is returned when argument type does not match with function's parameter type.
+type TypeError struct {
+	// Function is name of the function.
+	Function string
+	// Index is position of the argument.
+	Index int
+	// Variadic is true when argument is in variadic part of the function.
+	Variadic bool
+	Expected reflect.Type
+	Actual   reflect.Type
+}
+
+func (e *TypeError) Error() string {
+	kind := "function"
+	if e.Variadic {
+		kind = "variadic function"
+	}
+
+	return fmt.Sprintf("index %d argument %s type mismatch with %s %s type", e.Index, e.Actual, kind, e.Expected)
+}
+
+// PipeError is returned from pipes with the failed step.
+type PipeError struct {
+	// Step is the index of the failed step.
+	Step     int
+	Function string
+	Err      error
+}
+
+func (e *PipeError) Error() string {
+	return fmt.Sprintf("pipe step %d function %s: %v", e.Step, e.Function, e.Err)
+}
+
+func (e *PipeError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,136 @@
+package call
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCallError(t *testing.T) {
+	tests := []struct {
+		name      string
+		modify    func(*Reg)
+		args      []string
+		want      *CallError
+		wantIs    error
+		wantPanic bool
+	}{
+		{
+			name:   "function not found",
+			want:   &CallError{Function: "test", Stage: StageResolve},
+			wantIs: ErrFunctionNotFound,
+		},
+		{
+			name: "argument not found",
+			modify: func(r *Reg) {
+				r.AddFunction("test", func(string) {})
+			},
+			args:   []string{"arg:index=0"},
+			want:   &CallError{Function: "test", Argument: "arg", Stage: StageResolve},
+			wantIs: ErrArgumentNotFound,
+		},
+		{
+			name: "option error",
+			modify: func(r *Reg) {
+				r.AddFunction("test", func(string) {}).AddArgument("arg", "arg")
+			},
+			args:   []string{"arg:index=0;xyz"},
+			want:   &CallError{Function: "test", Argument: "arg", Option: "index", Stage: StageOption},
+			wantIs: nil,
+		},
+		{
+			name: "option not found",
+			modify: func(r *Reg) {
+				r.AddFunction("test", func(string) {}).AddArgument("arg", []string{"arg"})
+			},
+			args:   []string{"arg:index=0;xyz"},
+			want:   &CallError{Function: "test", Argument: "arg", Option: "xyz", Stage: StageOption},
+			wantIs: ErrOptionNotFound,
+		},
+		{
+			name: "argument count mismatch",
+			modify: func(r *Reg) {
+				r.AddFunction("test", func(string, string) {}).AddArgument("arg", "arg")
+			},
+			args:   []string{"arg"},
+			want:   &CallError{Function: "test", Stage: StageTypeCheck},
+			wantIs: ErrArgCountMismatch,
+		},
+		{
+			name: "option panic",
+			modify: func(r *Reg) {
+				r.AddOption("panic", func([]reflect.Value, ...string) ([]reflect.Value, error) {
+					panic("option panic")
+				})
+				r.AddFunction("test", func(string) {}).AddArgument("arg", "arg")
+			},
+			args:      []string{"arg:panic"},
+			want:      &CallError{Function: "test", Argument: "arg", Option: "panic", Stage: StageOption},
+			wantPanic: true,
+		},
+		{
+			name: "function panic",
+			modify: func(r *Reg) {
+				r.AddFunction("test", func(string) { panic("function panic") }).AddArgument("arg", "arg")
+			},
+			args:      []string{"arg"},
+			want:      &CallError{Function: "test", Stage: StageInvoke},
+			wantPanic: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewReg()
+			if tt.modify != nil {
+				tt.modify(r)
+			}
+
+			_, err := r.CallWithArgs("test", tt.args...)
+
+			var callErr *CallError
+			if !errors.As(err, &callErr) {
+				t.Fatalf("Reg.CallWithArgs() error = %v, want CallError", err)
+			}
+
+			if callErr.Function != tt.want.Function || callErr.Argument != tt.want.Argument ||
+				callErr.Option != tt.want.Option || callErr.Stage != tt.want.Stage {
+				t.Errorf("Reg.CallWithArgs() error = %+v, want %+v", callErr, tt.want)
+			}
+
+			if tt.wantIs != nil && !errors.Is(err, tt.wantIs) {
+				t.Errorf("Reg.CallWithArgs() error = %v, want %v", err, tt.wantIs)
+			}
+
+			var panicErr *PanicError
+			if errors.As(err, &panicErr) != tt.wantPanic {
+				t.Errorf("Reg.CallWithArgs() error = %v, wantPanic %v", err, tt.wantPanic)
+			}
+
+			if (len(callErr.Stack) > 0) != tt.wantPanic {
+				t.Errorf("Reg.CallWithArgs() stack = %s, wantPanic %v", callErr.Stack, tt.wantPanic)
+			}
+		})
+	}
+}
+
+func TestCallError_PrepareOptionNotFound(t *testing.T) {
+	r := NewReg().AddFunction("test", func(string) {}).AddArgument("arg", "arg")
+
+	// Prepare reports unknown options before running the options
+	_, err := r.Prepare("test", "arg:index=0;xyz")
+
+	var callErr *CallError
+	if !errors.As(err, &callErr) {
+		t.Fatalf("Reg.Prepare() error = %v, want CallError", err)
+	}
+
+	want := &CallError{Function: "test", Argument: "arg", Option: "xyz", Stage: StageOption}
+	if callErr.Function != want.Function || callErr.Argument != want.Argument ||
+		callErr.Option != want.Option || callErr.Stage != want.Stage {
+		t.Errorf("Reg.Prepare() error = %+v, want %+v", callErr, want)
+	}
+
+	if !errors.Is(err, ErrOptionNotFound) {
+		t.Errorf("Reg.Prepare() error = %v, want %v", err, ErrOptionNotFound)
+	}
+}
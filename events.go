@@ -0,0 +1,245 @@
+package call
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// EventKind is the kind of changed registry entry.
+type EventKind string
+
+const (
+	// EventArgument is change of an argument.
+	EventArgument EventKind = "argument"
+	// EventFunction is change of a function.
+	EventFunction EventKind = "function"
+)
+
+// Event is a change in the registry.
+//
+// Old and New are argument values or Func values of the functions and providers, Old is nil for
+// added entries and New is nil for deleted entries.
+// Providers are arguments, so their changes are EventArgument.
+type Event struct {
+	Kind    EventKind
+	Name    string
+	Old     any
+	New     any
+	Deleted bool
+}
+
+// subscription is a handler of the events.
+type subscription struct {
+	fn func(Event)
+	// removed subscription is not called for queued events.
+	removed atomic.Bool
+}
+
+// eventHandlers are subscriptions of the registry.
+type eventHandlers struct {
+	argument []*subscription
+	function []*subscription
+	delete   []*subscription
+	all      []*subscription
+}
+
+// OnArgumentChange adds handler which is called when argument or provider is added, replaced or deleted.
+//
+// Handlers are called synchronously after the change in the order they are added,
+// use EventChan to get events from a channel.
+// Events are delivered one by one in change order, if another change is emitting
+// its events the event is delivered by it after them. Changes of scopes are not sent to parent.
+// Handlers added with On methods stay for the registry's lifetime, use Subscribe to remove them.
+func (r *Reg) OnArgumentChange(fn func(Event)) *Reg {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.events.argument = append(r.events.argument, &subscription{fn: fn})
+
+	return r
+}
+
+// OnFunctionChange adds handler which is called when function is added, replaced or deleted.
+func (r *Reg) OnFunctionChange(fn func(Event)) *Reg {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.events.function = append(r.events.function, &subscription{fn: fn})
+
+	return r
+}
+
+// OnDelete adds handler which is called when argument, provider or function is deleted.
+func (r *Reg) OnDelete(fn func(Event)) *Reg {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.events.delete = append(r.events.delete, &subscription{fn: fn})
+
+	return r
+}
+
+// Subscribe adds handler which is called for every event like OnArgumentChange and returns
+// function which removes the handler.
+//
+// Handler is not called after unsubscribe returns, except the event which is being delivered.
+func (r *Reg) Subscribe(fn func(Event)) (unsubscribe func()) {
+	s := &subscription{fn: fn}
+
+	r.mutex.Lock()
+	r.events.all = append(r.events.all, s)
+	r.mutex.Unlock()
+
+	return func() {
+		s.removed.Store(true)
+
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+
+		for i, v := range r.events.all {
+			if v == s {
+				r.events.all = append(r.events.all[:i:i], r.events.all[i+1:]...)
+
+				break
+			}
+		}
+	}
+}
+
+// EventChan returns handler which sends events to ch and cancel which stops sending.
+//
+// Sending waits the receiver, so events are not lost and a slow receiver slows delivery of
+// the events, use EventChanDrop to not wait.
+// After cancel returns no events are sent, so ch can be closed. Handler is still registered,
+// use it with Subscribe and call unsubscribe to remove it.
+func EventChan(ch chan<- Event) (handler func(Event), cancel func()) {
+	return eventChan(ch, false, nil)
+}
+
+// EventChanDrop is like EventChan but it doesn't wait the receiver.
+//
+// Events are dropped when ch is full and counted in dropped, dropped can be nil.
+func EventChanDrop(ch chan<- Event, dropped *atomic.Uint64) (handler func(Event), cancel func()) {
+	return eventChan(ch, true, dropped)
+}
+
+func eventChan(ch chan<- Event, drop bool, dropped *atomic.Uint64) (handler func(Event), cancel func()) {
+	var (
+		mutex    sync.Mutex
+		canceled bool
+		once     sync.Once
+	)
+
+	done := make(chan struct{})
+
+	handler = func(e Event) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if canceled {
+			return
+		}
+
+		if drop {
+			select {
+			case ch <- e:
+			default:
+				if dropped != nil {
+					dropped.Add(1)
+				}
+			}
+
+			return
+		}
+
+		select {
+		case ch <- e:
+		case <-done:
+		}
+	}
+
+	cancel = func() {
+		// stop waiting send before taking the lock
+		once.Do(func() { close(done) })
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		canceled = true
+	}
+
+	return handler, cancel
+}
+
+// handlers returns handlers of the event, registry should be locked.
+func (r *Reg) handlers(e Event) []*subscription {
+	var handlers []*subscription
+
+	switch e.Kind {
+	case EventArgument:
+		handlers = append(handlers, r.events.argument...)
+	case EventFunction:
+		handlers = append(handlers, r.events.function...)
+	}
+
+	if e.Deleted {
+		handlers = append(handlers, r.events.delete...)
+	}
+
+	return append(handlers, r.events.all...)
+}
+
+// queuedEvent is an event with handlers which are taken at the change.
+type queuedEvent struct {
+	event    Event
+	handlers []*subscription
+}
+
+// queueEvent queues event of the change, registry should be locked.
+//
+// Events are queued in change order, so handlers get them in the same order.
+func (r *Reg) queueEvent(e Event) {
+	handlers := r.handlers(e)
+	if len(handlers) == 0 {
+		return
+	}
+
+	r.eventQueue = append(r.eventQueue, queuedEvent{event: e, handlers: handlers})
+}
+
+// emit calls handlers of the queued events, registry should not be locked.
+//
+// Only one goroutine emits events of the registry, others return and their events are emitted by it.
+// Changes in handlers are queued and emitted after the current event.
+func (r *Reg) emit() {
+	r.mutex.Lock()
+	if r.emitting {
+		r.mutex.Unlock()
+
+		return
+	}
+
+	r.emitting = true
+
+	defer func() {
+		r.mutex.Lock()
+		r.emitting = false
+		r.mutex.Unlock()
+	}()
+
+	for len(r.eventQueue) > 0 {
+		q := r.eventQueue[0]
+		r.eventQueue = r.eventQueue[1:]
+		r.mutex.Unlock()
+
+		for _, s := range q.handlers {
+			if !s.removed.Load() {
+				s.fn(q.event)
+			}
+		}
+
+		r.mutex.Lock()
+	}
+
+	r.mutex.Unlock()
+}
@@ -0,0 +1,273 @@
+package call
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestReg_Events(t *testing.T) {
+	var argEvents, fnEvents, deleteEvents []Event
+
+	reg := NewReg().
+		OnArgumentChange(func(e Event) { argEvents = append(argEvents, e) }).
+		OnFunctionChange(func(e Event) { fnEvents = append(fnEvents, e) }).
+		OnDelete(func(e Event) { deleteEvents = append(deleteEvents, e) })
+
+	reg.AddArgument("port", 80).
+		AddArgument("port:index=0", 8080).
+		DeleteArgument("port").
+		DeleteArgument("missing")
+
+	wantArgs := []Event{
+		{Kind: EventArgument, Name: "port", New: 80},
+		{Kind: EventArgument, Name: "port", Old: 80, New: 8080},
+		{Kind: EventArgument, Name: "port", Old: 8080, Deleted: true},
+	}
+	if !reflect.DeepEqual(argEvents, wantArgs) {
+		t.Errorf("argument events = %+v, want %+v", argEvents, wantArgs)
+	}
+
+	reg.AddFunction("fn", func() int { return 1 }, "a").
+		AddFunctionAuto("fn", func() int { return 2 }).
+		DeleteFunction("fn").
+		DeleteFunction("missing")
+
+	if len(fnEvents) != 3 {
+		t.Fatalf("function events = %+v, want 3 events", fnEvents)
+	}
+
+	if fnEvents[0].Old != nil || fnEvents[0].New.(Func).Args[0] != "a" {
+		t.Errorf("add event = %+v", fnEvents[0])
+	}
+
+	if fnEvents[1].Old.(Func).Auto || !fnEvents[1].New.(Func).Auto {
+		t.Errorf("replace event = %+v", fnEvents[1])
+	}
+
+	if !fnEvents[2].Deleted || fnEvents[2].New != nil || !fnEvents[2].Old.(Func).Auto {
+		t.Errorf("delete event = %+v", fnEvents[2])
+	}
+
+	if len(deleteEvents) != 2 || deleteEvents[0].Kind != EventArgument || deleteEvents[1].Kind != EventFunction {
+		t.Errorf("delete events = %+v", deleteEvents)
+	}
+}
+
+func TestReg_EventsChan(t *testing.T) {
+	ch := make(chan Event, 2)
+
+	handler, cancel := EventChan(ch)
+
+	reg := NewReg().OnArgumentChange(handler)
+
+	scope := reg.NewScope()
+	scope.AddArgument("scoped", 1)
+
+	reg.AddArgument("config", "a")
+
+	// handler can read registry
+	reg.OnArgumentChange(func(e Event) {
+		if v, _ := reg.GetArgument(e.Name); v != e.New {
+			t.Errorf("argument %s = %v, want %v", e.Name, v, e.New)
+		}
+	})
+	reg.AddArgument("config", "b")
+
+	for _, want := range []Event{
+		{Kind: EventArgument, Name: "config", New: "a"},
+		{Kind: EventArgument, Name: "config", Old: "a", New: "b"},
+	} {
+		if got := <-ch; !reflect.DeepEqual(got, want) {
+			t.Errorf("event = %+v, want %+v", got, want)
+		}
+	}
+
+	select {
+	case e := <-ch:
+		t.Errorf("unexpected event %+v", e)
+	default:
+	}
+
+	cancel()
+	close(ch)
+
+	// canceled handler doesn't send to closed channel
+	reg.AddArgument("config", "c")
+}
+
+func TestReg_EventsChanWait(t *testing.T) {
+	ch := make(chan Event)
+
+	handler, cancel := EventChan(ch)
+
+	reg := NewReg()
+	unsubscribe := reg.Subscribe(handler)
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		// change waits the receiver, events are not lost
+		reg.AddArgument("a", 1).AddArgument("b", 2)
+	}()
+
+	for _, name := range []string{"a", "b"} {
+		if got := <-ch; got.Name != name {
+			t.Errorf("event = %+v, want %s", got, name)
+		}
+	}
+
+	<-done
+
+	go reg.AddArgument("c", 3)
+
+	// cancel stops waiting send
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	unsubscribe()
+	close(ch)
+
+	reg.AddArgument("d", 4)
+
+	reg.mutex.RLock()
+	defer reg.mutex.RUnlock()
+
+	if len(reg.events.all) != 0 {
+		t.Errorf("subscriptions = %d, want 0", len(reg.events.all))
+	}
+}
+
+func TestReg_EventsChanDrop(t *testing.T) {
+	var dropped atomic.Uint64
+
+	ch := make(chan Event, 2)
+
+	handler, cancel := EventChanDrop(ch, &dropped)
+	defer cancel()
+
+	reg := NewReg().OnArgumentChange(handler)
+
+	// full channel drops events without blocking the change
+	reg.AddArgument("config", "a").
+		AddArgument("config", "b").
+		AddArgument("config", "c")
+
+	if len(ch) != 2 || dropped.Load() != 1 {
+		t.Errorf("channel has %d events and %d dropped, want 2 and 1", len(ch), dropped.Load())
+	}
+}
+
+func TestReg_Subscribe(t *testing.T) {
+	var events []Event
+
+	reg := NewReg()
+	unsubscribe := reg.Subscribe(func(e Event) { events = append(events, e) })
+
+	reg.AddArgument("a", 1).AddFunction("f", func() {}).DeleteArgument("a")
+
+	unsubscribe()
+	unsubscribe()
+
+	reg.AddArgument("b", 2)
+
+	if len(events) != 3 || events[0].Kind != EventArgument || events[1].Kind != EventFunction || !events[2].Deleted {
+		t.Errorf("events = %+v", events)
+	}
+}
+
+func TestReg_EventsProvider(t *testing.T) {
+	var events []Event
+
+	reg := NewReg().OnArgumentChange(func(e Event) { events = append(events, e) })
+
+	reg.AddArgument("db", "dsn").
+		AddSingleton("db", func() string { return "singleton" }).
+		AddProvider("db", func() string { return "provider" }, "a").
+		AddArgument("db", "value").
+		AddProvider("db", func() string { return "provider" }).
+		DeleteProvider("db").
+		DeleteProvider("missing")
+
+	if len(events) != 6 {
+		t.Fatalf("argument events = %+v, want 6 events", events)
+	}
+
+	if events[1].Old != "dsn" || events[1].New.(Func).Fn.Kind() != reflect.Func {
+		t.Errorf("provider replaces argument event = %+v", events[1])
+	}
+
+	if events[2].Old.(Func).Args != nil || events[2].New.(Func).Args[0] != "a" {
+		t.Errorf("provider replaces provider event = %+v", events[2])
+	}
+
+	if events[3].Old.(Func).Args[0] != "a" || events[3].New != "value" {
+		t.Errorf("argument replaces provider event = %+v", events[3])
+	}
+
+	if !events[5].Deleted || events[5].New != nil || events[5].Old.(Func).Fn.Kind() != reflect.Func {
+		t.Errorf("delete provider event = %+v", events[5])
+	}
+}
+
+func TestReg_EventsOrder(t *testing.T) {
+	var events []Event
+
+	reg := NewReg()
+	reg.OnArgumentChange(func(e Event) {
+		// events are emitted one by one
+		events = append(events, e)
+	})
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			reg.AddArgument("config", i)
+		}(i)
+	}
+
+	wg.Wait()
+
+	if len(events) != 50 {
+		t.Fatalf("events = %d, want 50", len(events))
+	}
+
+	// every event continues from the previous one
+	for i := 1; i < len(events); i++ {
+		if events[i].Old != events[i-1].New {
+			t.Fatalf("event %d old = %v, previous new = %v", i, events[i].Old, events[i-1].New)
+		}
+	}
+
+	if v, _ := reg.GetArgument("config"); events[len(events)-1].New != v {
+		t.Errorf("last event new = %v, argument = %v", events[len(events)-1].New, v)
+	}
+}
+
+func TestReg_EventsNested(t *testing.T) {
+	var names []string
+
+	reg := NewReg()
+	reg.OnArgumentChange(func(e Event) {
+		names = append(names, e.Name)
+
+		// changes in handlers are emitted after the current event
+		if e.Name == "a" {
+			reg.AddArgument("b", 1)
+		}
+	})
+
+	reg.AddArgument("a", 1)
+
+	if want := []string{"a", "b"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("events = %v, want %v", names, want)
+	}
+}
@@ -33,3 +33,22 @@ func Example() {
 	// Output:
 	// 3 <nil>
 }
+
+func ExampleInvoke() {
+	reg := call.NewReg().
+		AddArgument("a", 6).
+		AddArgument("b", 2).
+		AddFunction("", divide, "a", "b")
+
+	// trailing error is returned as error
+	v, err := call.Invoke[int](reg, "divide")
+	if err != nil {
+		fmt.Println(err)
+
+		return
+	}
+
+	fmt.Println(v)
+	// Output:
+	// 3
+}
@@ -0,0 +1,249 @@
+package call
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// tagName is the struct tag key of the injected fields.
+const tagName = "call"
+
+// tagSpaces separates expression and flags of the tag.
+const tagSpaces = " \t"
+
+// injectableTypes caches struct types which have call tags.
+var injectableTypes sync.Map
+
+// fieldTag is parsed `call` tag.
+//
+//	`call:"argName:option=value optional default=value"`
+type fieldTag struct {
+	// expr is argument with options.
+	expr string
+	// optional field is not set when argument not found.
+	optional bool
+	// defaultValue is used when argument not found.
+	defaultValue *string
+}
+
+// parseFieldTag splits the tag to expression and flags.
+//
+// Expression ends at the first space which is not quoted or escaped,
+// so option arguments can have spaces like `call:"m:index=\"a b\""`.
+// Values of the flags can be quoted too.
+func parseFieldTag(tag string) (fieldTag, error) {
+	p := &exprParser{expr: strings.TrimSpace(tag)}
+
+	if _, err := p.word(tagSpaces); err != nil {
+		return fieldTag{}, err
+	}
+
+	if p.pos == 0 {
+		return fieldTag{}, fmt.Errorf("empty tag")
+	}
+
+	ft := fieldTag{expr: p.expr[:p.pos]}
+
+	for !p.done() {
+		if strings.IndexByte(tagSpaces, p.expr[p.pos]) >= 0 {
+			p.pos++
+
+			continue
+		}
+
+		flag, err := p.word(tagSpaces)
+		if err != nil {
+			return fieldTag{}, err
+		}
+
+		switch {
+		case flag == "optional":
+			ft.optional = true
+		case strings.HasPrefix(flag, "default="):
+			v := strings.TrimPrefix(flag, "default=")
+			ft.defaultValue = &v
+		default:
+			return fieldTag{}, fmt.Errorf("unknown tag flag %s", flag)
+		}
+	}
+
+	return ft, nil
+}
+
+// InjectStruct fills fields of the struct which have `call` tag.
+//
+// Tag has argument name with options and space separated flags.
+// Optional fields are not set when argument is not found and default is parsed to field type.
+//
+//	type Deps struct {
+//		DB   *sql.DB       `call:"db"`
+//		Port int           `call:"config:index=port default=8080"`
+//		Log  *slog.Logger  `call:"logger optional"`
+//	}
+//
+// Functions which have parameter of this kind of struct or pointer get it injected without argument.
+func (r *Reg) InjectStruct(ptr any) error {
+	v := reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("inject value should be a pointer to struct, got %T", ptr)
+	}
+
+	return r.injectStruct(&callState{ctx: context.Background()}, v.Elem())
+}
+
+func (r *Reg) injectStruct(state *callState, v reflect.Value) error {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag, ok := field.Tag.Lookup(tagName)
+		if !ok {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				if err := r.injectStruct(state, v.Field(i)); err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+
+		if tag == "-" {
+			continue
+		}
+
+		if !field.IsExported() {
+			return fmt.Errorf("field %s is not exported", field.Name)
+		}
+
+		if err := r.injectField(state, v.Field(i), tag); err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *Reg) injectField(state *callState, v reflect.Value, tag string) error {
+	ft, err := parseFieldTag(tag)
+	if err != nil {
+		return err
+	}
+
+	p, err := r.compileArg(ft.expr)
+	if err != nil {
+		if _, ok := err.(*SyntaxError); ok {
+			return err
+		}
+
+		return fmt.Errorf("argument %s option %w", p.name, err)
+	}
+
+	argPure := p.name
+
+	values, stage, err := r.resolveArg(state, p)
+	if err == ErrArgumentNotFound {
+		if ft.defaultValue != nil {
+			defaultV, errParse := parseValue(*ft.defaultValue, v.Type())
+			if errParse != nil {
+				return fmt.Errorf("default value: %w", errParse)
+			}
+
+			v.Set(defaultV)
+
+			return nil
+		}
+
+		if ft.optional {
+			return nil
+		}
+	}
+
+	if err != nil && stage == StageResolve {
+		return fmt.Errorf("argument %s: %w", argPure, err)
+	}
+
+	if err != nil {
+		return fmt.Errorf("argument %s option %w", argPure, err)
+	}
+
+	if len(values) != 1 {
+		return fmt.Errorf("argument %s has %d values", argPure, len(values))
+	}
+
+	value := values[0]
+	if value.Kind() == reflect.Interface {
+		value = value.Elem()
+	}
+
+	if !value.IsValid() {
+		return nil
+	}
+
+	if rawV, ok, err := parseRaw(value, v.Type()); ok {
+		if err != nil {
+			return fmt.Errorf("value %s: %w", rawString(value), err)
+		}
+
+		v.Set(rawV)
+
+		return nil
+	}
+
+	switch {
+	case value.Type().AssignableTo(v.Type()):
+		v.Set(value)
+	case r.isConversion() && canConvert(value, v.Type()):
+		v.Set(value.Convert(v.Type()))
+	default:
+		return fmt.Errorf("argument %s type %s is not assignable to %s", argPure, value.Type(), v.Type())
+	}
+
+	return nil
+}
+
+// isInjectable reports t is struct or pointer to struct which has `call` tags.
+func isInjectable(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+
+	if v, ok := injectableTypes.Load(t); ok {
+		return v.(bool)
+	}
+
+	injectable := false
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if _, ok := field.Tag.Lookup(tagName); ok || (field.Anonymous && field.Type.Kind() == reflect.Struct && isInjectable(field.Type)) {
+			injectable = true
+
+			break
+		}
+	}
+
+	injectableTypes.Store(t, injectable)
+
+	return injectable
+}
+
+// newInjected creates value of the t and injects its fields.
+func (r *Reg) newInjected(state *callState, t reflect.Type) (reflect.Value, error) {
+	if t.Kind() == reflect.Pointer {
+		v := reflect.New(t.Elem())
+
+		return v, r.injectStruct(state, v.Elem())
+	}
+
+	v := reflect.New(t).Elem()
+
+	return v, r.injectStruct(state, v)
+}
@@ -0,0 +1,237 @@
+package call
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testDeps struct {
+	Name    string        `call:"name"`
+	Port    int           `call:"ports:index=1"`
+	Timeout time.Duration `call:"timeout default=30s"`
+	Log     *string       `call:"log optional"`
+	Skip    string        `call:"-"`
+	Other   string
+	testEmbedded
+}
+
+type testEmbedded struct {
+	Count int `call:"count default=5"`
+}
+
+func TestReg_InjectStruct(t *testing.T) {
+	tests := []struct {
+		name       string
+		modify     func(*Reg)
+		ptr        any
+		want       any
+		wantErrStr string
+	}{
+		{
+			name: "inject fields",
+			modify: func(r *Reg) {
+				r.AddArgument("name", "test").
+					AddArgument("ports", []int{80, 443}).
+					AddProvider("count", func() int { return 3 })
+			},
+			ptr: &testDeps{},
+			want: &testDeps{
+				Name:         "test",
+				Port:         443,
+				Timeout:      30 * time.Second,
+				testEmbedded: testEmbedded{Count: 3},
+			},
+		},
+		{
+			name: "missing argument",
+			modify: func(r *Reg) {
+				r.AddArgument("ports", []int{80, 443})
+			},
+			ptr:        &testDeps{},
+			want:       &testDeps{},
+			wantErrStr: "field Name: argument name: argument not found",
+		},
+		{
+			name: "option error",
+			modify: func(r *Reg) {
+				r.AddArgument("name", "test").AddArgument("ports", []int{80})
+			},
+			ptr:        &testDeps{},
+			want:       &testDeps{Name: "test"},
+			wantErrStr: "field Port: argument ports option index; index out of range",
+		},
+		{
+			name: "type mismatch",
+			modify: func(r *Reg) {
+				r.AddArgument("name", 1)
+			},
+			ptr:        &testDeps{},
+			want:       &testDeps{},
+			wantErrStr: "field Name: argument name type int is not assignable to string",
+		},
+		{
+			name: "wrong default",
+			ptr: &struct {
+				V int `call:"v default=x"`
+			}{},
+			want: &struct {
+				V int `call:"v default=x"`
+			}{},
+			wantErrStr: `field V: default value: strconv.ParseInt: parsing "x": invalid syntax`,
+		},
+		{
+			name: "expression defaults",
+			modify: func(r *Reg) {
+				r.AddArgument("backup", "b")
+			},
+			ptr: &struct {
+				Name string        `call:"primary|backup"`
+				Wait time.Duration `call:"wait:default=1m"`
+				User string        `call:"user?"`
+			}{},
+			want: &struct {
+				Name string        `call:"primary|backup"`
+				Wait time.Duration `call:"wait:default=1m"`
+				User string        `call:"user?"`
+			}{Name: "b", Wait: time.Minute},
+		},
+		{
+			name: "quoted tag",
+			modify: func(r *Reg) {
+				r.AddArgument("m", map[string]string{"a b": "ab"})
+			},
+			ptr: &struct {
+				V string `call:"m:index=\"a b\" optional"`
+				D string `call:"d default=\"x y\""`
+			}{},
+			want: &struct {
+				V string `call:"m:index=\"a b\" optional"`
+				D string `call:"d default=\"x y\""`
+			}{V: "ab", D: "x y"},
+		},
+		{
+			name: "provider error",
+			modify: func(r *Reg) {
+				r.AddProvider("n", func() (int, error) { return 0, errors.New("boom") })
+			},
+			ptr: &struct {
+				N int `call:"n default=5"`
+				O int `call:"n optional"`
+			}{},
+			want: &struct {
+				N int `call:"n default=5"`
+				O int `call:"n optional"`
+			}{},
+			wantErrStr: "field N: argument n: provider n: boom",
+		},
+		{
+			name: "unknown flag",
+			ptr: &struct {
+				V int `call:"v required"`
+			}{},
+			want: &struct {
+				V int `call:"v required"`
+			}{},
+			wantErrStr: "field V: unknown tag flag required",
+		},
+		{
+			name: "unexported",
+			ptr: &struct {
+				v int `call:"v"`
+			}{},
+			want: &struct {
+				v int `call:"v"`
+			}{},
+			wantErrStr: "field v is not exported",
+		},
+		{
+			name:       "not pointer",
+			ptr:        testDeps{},
+			want:       testDeps{},
+			wantErrStr: "inject value should be a pointer to struct, got call.testDeps",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewReg()
+			if tt.modify != nil {
+				tt.modify(r)
+			}
+
+			err := r.InjectStruct(tt.ptr)
+			if (err != nil) != (tt.wantErrStr != "") {
+				t.Fatalf("Reg.InjectStruct() error = %v, wantErrStr %v", err, tt.wantErrStr)
+			}
+			if err != nil && err.Error() != tt.wantErrStr {
+				t.Errorf("Reg.InjectStruct() error = %v, wantErrStr %v", err, tt.wantErrStr)
+			}
+			if !reflect.DeepEqual(tt.ptr, tt.want) {
+				t.Errorf("Reg.InjectStruct() = %+v, want %+v", tt.ptr, tt.want)
+			}
+		})
+	}
+}
+
+func TestReg_CallInjected(t *testing.T) {
+	r := NewReg().
+		AddArgument("name", "test").
+		AddArgument("ports", []int{80, 443}).
+		AddArgument("prefix", "-").
+		AddFunction("value", func(prefix string, d testDeps) string {
+			return prefix + d.Name
+		}, "prefix").
+		AddFunction("pointer", func(d *testDeps, prefix string) string {
+			return d.Name + prefix + d.Timeout.String()
+		}, "prefix")
+
+	got, err := r.Call("value")
+	if err != nil {
+		t.Fatalf("Reg.Call() error = %v", err)
+	}
+	if want := []any{"-test"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Reg.Call() = %v, want %v", got, want)
+	}
+
+	got, err = r.Call("pointer")
+	if err != nil {
+		t.Fatalf("Reg.Call() error = %v", err)
+	}
+	if want := []any{"test-30s"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Reg.Call() = %v, want %v", got, want)
+	}
+
+	r.DeleteArgument("name")
+
+	wantErrStr := "resolve function value: parameter 1: field Name: argument name: argument not found"
+	if _, err := r.Call("value"); err == nil || err.Error() != wantErrStr {
+		t.Errorf("Reg.Call() error = %v, wantErrStr %v", err, wantErrStr)
+	}
+}
+
+func TestReg_CallInjectedExplicit(t *testing.T) {
+	r := NewReg().
+		AddArgument("deps", testDeps{Name: "explicit"}).
+		AddArgument("prefix", "-").
+		AddFunction("value", func(prefix string, d testDeps) string {
+			return prefix + d.Name
+		}, "prefix", "deps")
+
+	// explicit argument is used, registry doesn't have arguments of the tags
+	got, err := r.Call("value")
+	if err != nil {
+		t.Fatalf("Reg.Call() error = %v", err)
+	}
+	if want := []any{"-explicit"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Reg.Call() = %v, want %v", got, want)
+	}
+
+	got, err = r.CallWithArgs("value", "prefix", "deps")
+	if err != nil {
+		t.Fatalf("Reg.CallWithArgs() error = %v", err)
+	}
+	if want := []any{"-explicit"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Reg.CallWithArgs() = %v, want %v", got, want)
+	}
+}
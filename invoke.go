@@ -0,0 +1,123 @@
+package call
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// Invoke calls function and returns first return value as T.
+//
+// Without args, registered arguments of the function are used.
+// If function's last return value is an error, it is returned as error.
+func Invoke[T any](reg *Reg, name string, args ...string) (T, error) {
+	var v T
+
+	returns, err := invokeReturns(reg, name, args, 1)
+	if err != nil {
+		return v, err
+	}
+
+	return valueAs[T](name, 0, returns[0])
+}
+
+// Invoke2 calls function and returns first two return values as T1 and T2.
+//
+// Without args, registered arguments of the function are used.
+// If function's last return value is an error, it is returned as error.
+func Invoke2[T1, T2 any](reg *Reg, name string, args ...string) (T1, T2, error) {
+	var (
+		v1 T1
+		v2 T2
+	)
+
+	returns, err := invokeReturns(reg, name, args, 2)
+	if err != nil {
+		return v1, v2, err
+	}
+
+	if v1, err = valueAs[T1](name, 0, returns[0]); err != nil {
+		return v1, v2, err
+	}
+
+	v2, err = valueAs[T2](name, 1, returns[1])
+
+	return v1, v2, err
+}
+
+// GetArgumentAs returns argument with name as T.
+func GetArgumentAs[T any](reg *Reg, name string) (T, error) {
+	var v T
+
+	arg, ok := reg.GetArgument(name)
+	if !ok {
+		return v, fmt.Errorf("%w: %s", ErrArgumentNotFound, name)
+	}
+
+	if arg == nil {
+		return v, nil
+	}
+
+	v, ok = arg.(T)
+	if !ok {
+		return v, fmt.Errorf("argument %s type %T is not %s", name, arg, reflect.TypeOf(&v).Elem())
+	}
+
+	return v, nil
+}
+
+// invokeReturns calls function and returns values without trailing error.
+func invokeReturns(reg *Reg, name string, args []string, count int) ([]reflect.Value, error) {
+	if len(args) == 0 {
+		f, _ := reg.GetFunction(name)
+		args = f.Args
+	}
+
+	returns, err := reg.call(context.Background(), false, name, args)
+	if err != nil {
+		return nil, err
+	}
+
+	returns, err = splitError(returns)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(returns) < count {
+		return nil, fmt.Errorf("function %s returns %d values, want %d", name, len(returns), count)
+	}
+
+	return returns, nil
+}
+
+// splitError removes trailing error from return values and returns it as error.
+func splitError(returns []reflect.Value) ([]reflect.Value, error) {
+	if len(returns) == 0 || returns[len(returns)-1].Type() != errorType {
+		return returns, nil
+	}
+
+	errV := returns[len(returns)-1]
+	if !errV.IsNil() {
+		return nil, errV.Interface().(error)
+	}
+
+	return returns[:len(returns)-1], nil
+}
+
+// valueAs converts reflect value to T.
+func valueAs[T any](name string, index int, v reflect.Value) (T, error) {
+	var ret T
+
+	if !v.IsValid() || (v.Kind() == reflect.Interface && v.IsNil()) {
+		return ret, nil
+	}
+
+	ret, ok := v.Interface().(T)
+	if !ok {
+		return ret, fmt.Errorf("function %s return index %d type %s is not %s", name, index, v.Type(), reflect.TypeOf(&ret).Elem())
+	}
+
+	return ret, nil
+}
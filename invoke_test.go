@@ -0,0 +1,119 @@
+package call
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestInvoke(t *testing.T) {
+	errDivide := errors.New("divide by zero")
+
+	r := NewReg().
+		AddArgument("a", 6).
+		AddArgument("b", 2).
+		AddArgument("zero", 0).
+		AddFunction("divide", func(a, b int) (int, error) {
+			if b == 0 {
+				return 0, errDivide
+			}
+
+			return a / b, nil
+		}, "a", "b").
+		AddFunction("divmod", func(a, b int) (int, int, error) {
+			return a / b, a % b, nil
+		}, "a", "b").
+		AddFunction("error", func() error { return nil }).
+		AddFunction("any", func() any { return nil })
+
+	tests := []struct {
+		name       string
+		call       func() (any, error)
+		want       any
+		wantErr    error
+		wantErrStr string
+	}{
+		{
+			name: "registered arguments",
+			call: func() (any, error) { return Invoke[int](r, "divide") },
+			want: 3,
+		},
+		{
+			name: "with arguments",
+			call: func() (any, error) { return Invoke[int](r, "divide", "a", "a") },
+			want: 1,
+		},
+		{
+			name:    "returned error",
+			call:    func() (any, error) { return Invoke[int](r, "divide", "a", "zero") },
+			want:    0,
+			wantErr: errDivide,
+		},
+		{
+			name:    "call error",
+			call:    func() (any, error) { return Invoke[int](r, "missing") },
+			want:    0,
+			wantErr: ErrFunctionNotFound,
+		},
+		{
+			name:       "wrong type",
+			call:       func() (any, error) { return Invoke[string](r, "divide") },
+			want:       "",
+			wantErrStr: "function divide return index 0 type int is not string",
+		},
+		{
+			name:       "no value",
+			call:       func() (any, error) { return Invoke[int](r, "error") },
+			want:       0,
+			wantErrStr: "function error returns 0 values, want 1",
+		},
+		{
+			name: "nil interface",
+			call: func() (any, error) { return Invoke[any](r, "any") },
+			want: nil,
+		},
+		{
+			name: "two values",
+			call: func() (any, error) {
+				v1, v2, err := Invoke2[int, int](r, "divmod", "a", "b")
+
+				return fmt.Sprint(v1, v2), err
+			},
+			want: "3 0",
+		},
+		{
+			name: "argument",
+			call: func() (any, error) { return GetArgumentAs[int](r, "a") },
+			want: 6,
+		},
+		{
+			name:    "argument not found",
+			call:    func() (any, error) { return GetArgumentAs[int](r, "c") },
+			want:    0,
+			wantErr: ErrArgumentNotFound,
+		},
+		{
+			name:       "argument wrong type",
+			call:       func() (any, error) { return GetArgumentAs[string](r, "a") },
+			want:       "",
+			wantErrStr: "argument a type int is not string",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.call()
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Invoke() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErrStr != "" && (err == nil || err.Error() != tt.wantErrStr) {
+				t.Errorf("Invoke() error = %v, wantErrStr %v", err, tt.wantErrStr)
+			}
+			if tt.wantErr == nil && tt.wantErrStr == "" && err != nil {
+				t.Errorf("Invoke() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Invoke() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
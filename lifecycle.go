@@ -0,0 +1,146 @@
+package call
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// stopper is implemented by values which need context to stop.
+type stopper interface {
+	Stop(ctx context.Context) error
+}
+
+// created is a singleton value created by the registry.
+type created struct {
+	name     string
+	provider *provider
+}
+
+// owned is an argument value closed by the registry.
+type owned struct {
+	name  string
+	value any
+}
+
+// AddArgumentOwned adds argument like AddArgument and registry takes ownership of the value.
+//
+// Value is closed in Close after singletons, if it implements Stop(ctx) error or io.Closer.
+func (r *Reg) AddArgumentOwned(name string, v any) *Reg {
+	r.AddArgument(name, v)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.owned = append(r.owned, owned{name: argumentName(name, r.GetDelimeter()), value: v})
+
+	return r
+}
+
+// OnStart adds hook which is called in Start.
+func (r *Reg) OnStart(fn func(context.Context) error) *Reg {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.onStart = append(r.onStart, fn)
+
+	return r
+}
+
+// OnStop adds hook which is called in Close, hooks are called in reverse order.
+func (r *Reg) OnStop(fn func(context.Context) error) *Reg {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.onStop = append(r.onStop, fn)
+
+	return r
+}
+
+// Start calls start hooks in order, it stops at first error.
+func (r *Reg) Start(ctx context.Context) error {
+	r.mutex.RLock()
+	hooks := append([]func(context.Context) error{}, r.onStart...)
+	r.mutex.RUnlock()
+
+	for i, hook := range hooks {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := hook(ctx); err != nil {
+			return fmt.Errorf("start hook %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// Close calls stop hooks in reverse order and after that closes singleton and owned argument values of the registry.
+//
+// Singleton values implementing Stop(ctx) error or io.Closer are closed in reverse creation order,
+// so values are closed before their dependencies. Values released by Dispose are closed after them.
+// Arguments added with AddArgumentOwned are closed after singletons in reverse order,
+// other arguments are not closed.
+// All hooks and values are closed even some of them fail, errors are joined.
+// Singletons are released, so they are created again in next usage.
+// Owned arguments stay registered but are not closed again.
+// Stop hooks are removed, so next Close doesn't call them again.
+func (r *Reg) Close(ctx context.Context) error {
+	r.mutex.Lock()
+	hooks := r.onStop
+	values := r.created
+	released := r.released
+	ownedValues := r.owned
+	r.onStop = nil
+	r.created = nil
+	r.released = nil
+	r.owned = nil
+	r.mutex.Unlock()
+
+	var errs []error
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i](ctx); err != nil {
+			errs = append(errs, fmt.Errorf("stop hook %d: %w", i, err))
+		}
+	}
+
+	for i := len(values) - 1; i >= 0; i-- {
+		v := values[i].provider.take()
+
+		if err := closeValue(ctx, v); err != nil {
+			errs = append(errs, fmt.Errorf("close %s: %w", values[i].name, err))
+		}
+	}
+
+	// released values are created before current singletons
+	for i := len(released) - 1; i >= 0; i-- {
+		if err := closeValue(ctx, released[i].value); err != nil {
+			errs = append(errs, fmt.Errorf("close %s: %w", released[i].name, err))
+		}
+	}
+
+	for i := len(ownedValues) - 1; i >= 0; i-- {
+		if err := closeValue(ctx, ownedValues[i].value); err != nil {
+			errs = append(errs, fmt.Errorf("close %s: %w", ownedValues[i].name, err))
+		}
+	}
+
+	r.Dispose()
+
+	return errors.Join(errs...)
+}
+
+// closeValue stops or closes the value.
+func closeValue(ctx context.Context, v any) error {
+	switch c := v.(type) {
+	case stopper:
+		return c.Stop(ctx)
+	case io.Closer:
+		return c.Close()
+	}
+
+	return nil
+}
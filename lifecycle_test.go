@@ -0,0 +1,196 @@
+package call
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testCloser struct {
+	name   string
+	closed *[]string
+	err    error
+}
+
+func (c *testCloser) Close() error {
+	*c.closed = append(*c.closed, c.name)
+
+	return c.err
+}
+
+type testStopper struct {
+	name   string
+	closed *[]string
+}
+
+func (s *testStopper) Stop(ctx context.Context) error {
+	*s.closed = append(*s.closed, s.name)
+
+	return ctx.Err()
+}
+
+func TestReg_Lifecycle(t *testing.T) {
+	var (
+		closed  []string
+		started []string
+	)
+
+	errPool := errors.New("pool close failed")
+
+	r := NewReg().
+		AddSingleton("pool", func() *testCloser {
+			return &testCloser{name: "pool", closed: &closed, err: errPool}
+		}).
+		AddSingleton("repo", func(*testCloser) *testStopper {
+			return &testStopper{name: "repo", closed: &closed}
+		}, "pool").
+		AddSingleton("name", func() string { return "name" }).
+		AddFunction("handler", func(*testStopper, string) {}, "repo", "name").
+		OnStart(func(context.Context) error {
+			started = append(started, "first")
+
+			return nil
+		}).
+		OnStart(func(context.Context) error {
+			started = append(started, "second")
+
+			return nil
+		}).
+		OnStop(func(context.Context) error {
+			closed = append(closed, "hook-1")
+
+			return nil
+		}).
+		OnStop(func(context.Context) error {
+			closed = append(closed, "hook-2")
+
+			return nil
+		})
+
+	if err := r.Start(context.Background()); err != nil {
+		t.Fatalf("Reg.Start() error = %v", err)
+	}
+	if want := []string{"first", "second"}; !reflect.DeepEqual(started, want) {
+		t.Errorf("Reg.Start() started = %v, want %v", started, want)
+	}
+
+	if _, err := r.Call("handler"); err != nil {
+		t.Fatalf("Reg.Call() error = %v", err)
+	}
+
+	err := r.Close(context.Background())
+	if !errors.Is(err, errPool) {
+		t.Errorf("Reg.Close() error = %v, want %v", err, errPool)
+	}
+	if want := "close pool: pool close failed"; err == nil || err.Error() != want {
+		t.Errorf("Reg.Close() error = %v, want %v", err, want)
+	}
+	if want := []string{"hook-2", "hook-1", "repo", "pool"}; !reflect.DeepEqual(closed, want) {
+		t.Errorf("Reg.Close() closed = %v, want %v", closed, want)
+	}
+
+	// singletons are created again
+	closed = nil
+	if _, err := r.Call("handler"); err != nil {
+		t.Fatalf("Reg.Call() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = r.Close(ctx)
+	if !errors.Is(err, context.Canceled) || !errors.Is(err, errPool) {
+		t.Errorf("Reg.Close() error = %v, want joined errors", err)
+	}
+	// stop hooks are called once
+	if want := []string{"repo", "pool"}; !reflect.DeepEqual(closed, want) {
+		t.Errorf("Reg.Close() closed = %v, want %v", closed, want)
+	}
+}
+
+func TestReg_Start(t *testing.T) {
+	errStart := errors.New("start failed")
+	called := false
+
+	r := NewReg().
+		OnStart(func(context.Context) error { return errStart }).
+		OnStart(func(context.Context) error {
+			called = true
+
+			return nil
+		})
+
+	err := r.Start(context.Background())
+	if !errors.Is(err, errStart) || err.Error() != "start hook 0: start failed" {
+		t.Errorf("Reg.Start() error = %v, want %v", err, errStart)
+	}
+	if called {
+		t.Errorf("Reg.Start() should stop at first error")
+	}
+}
+
+func TestReg_AddArgumentOwned(t *testing.T) {
+	var closed []string
+
+	r := NewReg().
+		AddArgumentOwned("db", &testCloser{name: "db", closed: &closed}).
+		AddArgumentOwned("cache", &testStopper{name: "cache", closed: &closed}).
+		AddArgument("client", &testCloser{name: "client", closed: &closed}).
+		AddSingleton("repo", func(*testCloser) *testStopper {
+			return &testStopper{name: "repo", closed: &closed}
+		}, "db").
+		AddFunction("handler", func(*testStopper) {}, "repo")
+
+	if _, err := r.Call("handler"); err != nil {
+		t.Fatalf("Reg.Call() error = %v", err)
+	}
+
+	if err := r.Close(context.Background()); err != nil {
+		t.Fatalf("Reg.Close() error = %v", err)
+	}
+	if want := []string{"repo", "cache", "db"}; !reflect.DeepEqual(closed, want) {
+		t.Errorf("Reg.Close() closed = %v, want %v", closed, want)
+	}
+
+	// owned arguments are closed once
+	closed = nil
+	if err := r.Close(context.Background()); err != nil {
+		t.Fatalf("Reg.Close() error = %v", err)
+	}
+	if len(closed) != 0 {
+		t.Errorf("Reg.Close() closed = %v, want none", closed)
+	}
+}
+
+func TestReg_DisposeClose(t *testing.T) {
+	var closed []string
+
+	r := NewReg().
+		AddSingleton("pool", func() *testCloser {
+			return &testCloser{name: "pool", closed: &closed}
+		}).
+		AddFunction("handler", func(*testCloser) {}, "pool")
+
+	if _, err := r.Call("handler"); err != nil {
+		t.Fatalf("Reg.Call() error = %v", err)
+	}
+
+	r.Dispose()
+
+	if len(closed) != 0 {
+		t.Errorf("Reg.Dispose() closed = %v, want none", closed)
+	}
+
+	// new value after dispose
+	if _, err := r.Call("handler"); err != nil {
+		t.Fatalf("Reg.Call() error = %v", err)
+	}
+
+	if err := r.Close(context.Background()); err != nil {
+		t.Fatalf("Reg.Close() error = %v", err)
+	}
+	if want := []string{"pool", "pool"}; !reflect.DeepEqual(closed, want) {
+		t.Errorf("Reg.Close() closed = %v, want %v", closed, want)
+	}
+}
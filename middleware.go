@@ -0,0 +1,112 @@
+package call
+
+import (
+	"context"
+	"reflect"
+)
+
+// Next calls the next middleware or the function and returns its return values.
+type Next func() ([]reflect.Value, error)
+
+// Middleware wraps function calls, args are resolved and checked arguments of the function.
+//
+// Args can be changed before calling next, they are checked again with the function's parameter types
+// and next returns an error of StageTypeCheck if they don't match.
+// Returned values are used as function's return values.
+type Middleware func(ctx context.Context, name string, args []reflect.Value, next Next) ([]reflect.Value, error)
+
+// Use adds middleware for every function call, providers included.
+//
+// Global middleware wraps function middleware, parent's middleware wraps scope's middleware
+// and first added middleware is the outermost one.
+func (r *Reg) Use(mw ...Middleware) *Reg {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.version.Add(1)
+
+	r.middleware = append(r.middleware, mw...)
+
+	return r
+}
+
+// UseFunction adds middleware for the function or provider with name.
+//
+// Function doesn't need to be registered before, so middleware is not an option of AddFunction
+// whose variadic parameters are argument names.
+func (r *Reg) UseFunction(name string, mw ...Middleware) *Reg {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.version.Add(1)
+
+	if r.fnMiddleware == nil {
+		r.fnMiddleware = make(map[string][]Middleware)
+	}
+
+	r.fnMiddleware[name] = append(r.fnMiddleware[name], mw...)
+
+	return r
+}
+
+// middlewareChain returns middleware of the function from outermost to innermost.
+func (r *Reg) middlewareChain(name string) []Middleware {
+	var global, local []Middleware
+
+	for reg := r; reg != nil; reg = reg.parent {
+		reg.mutex.RLock()
+		global = append(append([]Middleware{}, reg.middleware...), global...)
+		local = append(append([]Middleware{}, reg.fnMiddleware[name]...), local...)
+		reg.mutex.RUnlock()
+	}
+
+	return append(global, local...)
+}
+
+// bindError is type check error of the arguments changed by middleware.
+type bindError struct {
+	err error
+}
+
+func (e *bindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *bindError) Unwrap() error {
+	return e.err
+}
+
+// invokeMiddleware calls the function through the middleware.
+//
+// Arguments are checked again before calling the function, middleware can change them.
+func invokeMiddleware(ctx context.Context, name string, mw []Middleware, fn reflect.Value, args []reflect.Value, convert bool) ([]reflect.Value, error) {
+	next := func() ([]reflect.Value, error) {
+		if len(mw) > 0 {
+			if err := bindArgs(name, fn.Type(), args, convert); err != nil {
+				return nil, &bindError{err: err}
+			}
+		}
+
+		return invoke(fn, args)
+	}
+
+	for i := len(mw) - 1; i >= 0; i-- {
+		m, inner := mw[i], next
+		next = func() ([]reflect.Value, error) {
+			return callMiddleware(ctx, m, name, args, inner)
+		}
+	}
+
+	return next()
+}
+
+// callMiddleware calls middleware and recovers panic.
+func callMiddleware(ctx context.Context, mw Middleware, name string, args []reflect.Value, next Next) (ret []reflect.Value, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = newPanicError(rec)
+		}
+	}()
+
+	return mw(ctx, name, args, next)
+}
@@ -0,0 +1,172 @@
+package call
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestReg_Use(t *testing.T) {
+	var order []string
+
+	trace := func(tag string) Middleware {
+		return func(_ context.Context, name string, args []reflect.Value, next Next) ([]reflect.Value, error) {
+			order = append(order, tag+" "+name)
+
+			return next()
+		}
+	}
+
+	reg := NewReg().
+		UseFunction("add", trace("root local")).
+		Use(trace("root 1"), trace("root 2")).
+		AddArgument("a", 1).
+		AddArgument("b", 2).
+		AddFunction("add", func(a, b int) int { return a + b }, "a", "b")
+
+	scope := reg.NewScope().
+		Use(trace("scope")).
+		UseFunction("add", trace("scope local"))
+
+	got, err := scope.Call("add")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got[0] != 3 {
+		t.Errorf("add = %v, want 3", got[0])
+	}
+
+	want := []string{"root 1 add", "root 2 add", "scope add", "root local add", "scope local add"}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("order = %v, want %v", order, want)
+	}
+
+	order = nil
+
+	if _, err := reg.Call("add"); err != nil {
+		t.Fatal(err)
+	}
+
+	want = []string{"root 1 add", "root 2 add", "root local add"}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("parent order = %v, want %v", order, want)
+	}
+}
+
+func TestReg_UseBehavior(t *testing.T) {
+	errDenied := errors.New("denied")
+
+	tests := []struct {
+		name       string
+		mw         Middleware
+		want       []any
+		wantErr    error
+		wantErrStr string
+	}{
+		{
+			name: "change arguments",
+			mw: func(_ context.Context, _ string, args []reflect.Value, next Next) ([]reflect.Value, error) {
+				args[0] = reflect.ValueOf(10)
+
+				return next()
+			},
+			want: []any{12},
+		},
+		{
+			name: "change arguments type",
+			mw: func(_ context.Context, _ string, args []reflect.Value, next Next) ([]reflect.Value, error) {
+				args[0] = reflect.ValueOf("10")
+
+				return next()
+			},
+			wantErrStr: "typecheck function add: index 0 argument string type mismatch with function int type",
+		},
+		{
+			name: "change returns",
+			mw: func(_ context.Context, _ string, _ []reflect.Value, next Next) ([]reflect.Value, error) {
+				returns, err := next()
+				if err != nil {
+					return nil, err
+				}
+
+				return []reflect.Value{reflect.ValueOf(returns[0].Int() * 2)}, nil
+			},
+			want: []any{int64(6)},
+		},
+		{
+			name: "stop call",
+			mw: func(_ context.Context, name string, _ []reflect.Value, _ Next) ([]reflect.Value, error) {
+				return nil, fmt.Errorf("%s: %w", name, errDenied)
+			},
+			wantErr:    errDenied,
+			wantErrStr: "invoke function add: add: denied",
+		},
+		{
+			name: "panic",
+			mw: func(_ context.Context, _ string, _ []reflect.Value, _ Next) ([]reflect.Value, error) {
+				panic("middleware")
+			},
+			wantErrStr: "invoke function add: panic: middleware",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := NewReg().
+				Use(tt.mw).
+				AddArgument("a", 1).
+				AddArgument("b", 2).
+				AddFunction("add", func(a, b int) int { return a + b }, "a", "b")
+
+			got, err := reg.Call("add")
+			if err != nil {
+				if err.Error() != tt.wantErrStr {
+					t.Errorf("Reg.Call() error = %v, wantErrStr %v", err, tt.wantErrStr)
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("Reg.Call() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if tt.wantErrStr != "" {
+				t.Fatalf("Reg.Call() error = nil, wantErrStr %v", tt.wantErrStr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Reg.Call() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReg_UseProviderAndPlan(t *testing.T) {
+	var names []string
+
+	reg := NewReg().
+		AddProvider("a", func() int { return 1 }).
+		AddFunction("inc", func(a int) int { return a + 1 }, "a")
+
+	plan, err := reg.Prepare("inc", "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := plan.Call(); err != nil {
+		t.Fatal(err)
+	}
+
+	reg.Use(func(_ context.Context, name string, _ []reflect.Value, next Next) ([]reflect.Value, error) {
+		names = append(names, name)
+
+		return next()
+	})
+
+	if _, err := plan.Call(); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"a", "inc"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("names = %v, want %v", names, want)
+	}
+}
@@ -1,10 +1,11 @@
 package call
 
 import (
-	"fmt"
+	"context"
 	"reflect"
 	"strings"
 	"sync"
+	"sync/atomic"
 )
 
 // Options is enable to modify arguments when calling functions.
@@ -12,16 +13,29 @@ import (
 // Use options like this first value name after that `:` seperated and pass option arguments with `=`.
 //
 //	`hababam:option1=1,2,3;option2=value2`.
+//
+// Values can be quoted or escaped with backslash, check ParseArgExpr for the syntax.
 type Options struct {
-	option map[string]func([]reflect.Value, ...string) ([]reflect.Value, error)
-	mutex  sync.RWMutex
+	option    map[string]func([]reflect.Value, ...string) ([]reflect.Value, error)
+	optionCtx map[string]func(context.Context, []reflect.Value, ...string) ([]reflect.Value, error)
+	version   atomic.Uint64
+	mutex     sync.RWMutex
 }
 
-var _ Option = (*Options)(nil)
+var (
+	_ Option        = (*Options)(nil)
+	_ OptionContext = (*Options)(nil)
+	_ versioned     = (*Options)(nil)
+)
 
 func NewOptions() Option {
+	return newOptions()
+}
+
+func newOptions() *Options {
 	return &Options{
-		option: make(map[string]func([]reflect.Value, ...string) ([]reflect.Value, error)),
+		option:    make(map[string]func([]reflect.Value, ...string) ([]reflect.Value, error)),
+		optionCtx: make(map[string]func(context.Context, []reflect.Value, ...string) ([]reflect.Value, error)),
 	}
 }
 
@@ -31,28 +45,81 @@ func (o *Options) GetDelimeter() string {
 }
 
 // ParseOptions parses options from string with delimeter.
+//
+// Options are returned as `name=arg1,arg2`, quotes and escapes of the arguments are removed.
+// It returns nil when expression is invalid.
+//
+// Deprecated: use ParseArgExpr, it reports syntax errors and keeps option arguments separated.
 func (o *Options) ParseOption(name string) []string {
-	o.mutex.RLock()
-	defer o.mutex.RUnlock()
+	expr, err := ParseArgExpr(name)
+	if err != nil || len(expr.Options) == 0 {
+		return nil
+	}
 
-	sName := strings.SplitN(name, ":", 2)
+	options := make([]string, 0, len(expr.Options))
+	for _, option := range expr.Options {
+		if option.Args == nil {
+			options = append(options, option.Name)
 
-	if len(sName) == 1 {
-		return nil
+			continue
+		}
+
+		options = append(options, option.Name+"="+strings.Join(option.Args, ","))
 	}
 
-	return strings.Split(sName[1], ";")
+	return options
 }
 
 func (o *Options) AddOption(name string, fn func([]reflect.Value, ...string) ([]reflect.Value, error)) Option {
 	o.mutex.Lock()
 	defer o.mutex.Unlock()
 
+	o.init()
+
 	o.option[name] = fn
+	o.optionCtx[name] = func(_ context.Context, v []reflect.Value, args ...string) ([]reflect.Value, error) {
+		return fn(v, args...)
+	}
+	o.version.Add(1)
 
 	return o
 }
 
+// AddOptionContext adds option which gets context of the call.
+//
+// When calling without context, context.Background is passed.
+func (o *Options) AddOptionContext(name string, fn func(context.Context, []reflect.Value, ...string) ([]reflect.Value, error)) Option {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	o.init()
+
+	o.option[name] = func(v []reflect.Value, args ...string) ([]reflect.Value, error) {
+		return fn(context.Background(), v, args...)
+	}
+	o.optionCtx[name] = fn
+	o.version.Add(1)
+
+	return o
+}
+
+// init creates maps of the options, every option is kept in both maps
+// so lookups don't wrap functions.
+func (o *Options) init() {
+	if o.option == nil {
+		o.option = make(map[string]func([]reflect.Value, ...string) ([]reflect.Value, error))
+	}
+
+	if o.optionCtx == nil {
+		o.optionCtx = make(map[string]func(context.Context, []reflect.Value, ...string) ([]reflect.Value, error))
+	}
+}
+
+// Version returns number which changes when options are added.
+func (o *Options) Version() uint64 {
+	return o.version.Load()
+}
+
 func (o *Options) GetOption(name string) (func([]reflect.Value, ...string) ([]reflect.Value, error), bool) {
 	o.mutex.RLock()
 	defer o.mutex.RUnlock()
@@ -62,31 +129,56 @@ func (o *Options) GetOption(name string) (func([]reflect.Value, ...string) ([]re
 	return fn, ok
 }
 
+// GetOptionContext returns option with name, options without context are wrapped when they are added.
+func (o *Options) GetOptionContext(name string) (func(context.Context, []reflect.Value, ...string) ([]reflect.Value, error), bool) {
+	o.mutex.RLock()
+	defer o.mutex.RUnlock()
+
+	if fn, ok := o.optionCtx[name]; ok {
+		return fn, true
+	}
+
+	// options given without AddOption
+	fn, ok := o.option[name]
+	if !ok {
+		return nil, false
+	}
+
+	return func(_ context.Context, v []reflect.Value, args ...string) ([]reflect.Value, error) {
+		return fn(v, args...)
+	}, true
+}
+
 func (o *Options) VisitOptions(arg string, v any) ([]reflect.Value, error) {
+	return o.VisitOptionsContext(context.Background(), arg, v)
+}
+
+// VisitOptionsContext applies options of the arg to the v.
+//
+// ctx checked before each option, so chain stops when ctx is done.
+func (o *Options) VisitOptionsContext(ctx context.Context, arg string, v any) ([]reflect.Value, error) {
 	var err error
 
 	vValue := []reflect.Value{reflect.ValueOf(v)}
 
-	options := o.ParseOption(arg)
-
-	for _, option := range options {
-		opt := strings.SplitN(option, "=", 2)
-		optName := opt[0]
-
-		var optVariables []string
+	expr, err := ParseArgExpr(arg)
+	if err != nil {
+		return nil, err
+	}
 
-		if len(opt) > 1 {
-			optVariables = strings.Split(opt[1], ",")
+	for _, option := range expr.Options {
+		if err := ctx.Err(); err != nil {
+			return nil, err
 		}
 
-		optionFn, ok := o.GetOption(optName)
+		optionFn, ok := o.GetOptionContext(option.Name)
 		if !ok {
-			return nil, fmt.Errorf("option %s not found", optName)
+			return nil, &OptionError{Option: option.Name, Err: ErrOptionNotFound}
 		}
 
-		vValue, err = optionFn(vValue, optVariables...)
+		vValue, err = callOption(ctx, optionFn, vValue, option.Args)
 		if err != nil {
-			return nil, fmt.Errorf("%s; %w", optName, err)
+			return nil, &OptionError{Option: option.Name, Err: err}
 		}
 
 		if vValue == nil {
@@ -96,3 +188,19 @@ func (o *Options) VisitOptions(arg string, v any) ([]reflect.Value, error) {
 
 	return vValue, nil
 }
+
+// callOption calls option function and recovers panic.
+func callOption(
+	ctx context.Context,
+	fn func(context.Context, []reflect.Value, ...string) ([]reflect.Value, error),
+	v []reflect.Value,
+	args []string,
+) (ret []reflect.Value, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = newPanicError(rec)
+		}
+	}()
+
+	return fn(ctx, v, args...)
+}
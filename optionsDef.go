@@ -4,10 +4,23 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
 )
 
 // OptionGetIndex returns value by index from slice, array or map.
+//
+// Map keys are converted to the key type of the map, missing keys return invalid value
+// which turns into zero value of the parameter.
 func OptionGetIndex(v []reflect.Value, args ...string) ([]reflect.Value, error) {
+	return getIndex(v, args, false)
+}
+
+// OptionGetIndexStrict is like OptionGetIndex but returns error for missing map keys.
+func OptionGetIndexStrict(v []reflect.Value, args ...string) ([]reflect.Value, error) {
+	return getIndex(v, args, true)
+}
+
+func getIndex(v []reflect.Value, args []string, strict bool) ([]reflect.Value, error) {
 	if len(v) == 0 {
 		return nil, fmt.Errorf("no value")
 	}
@@ -16,7 +29,7 @@ func OptionGetIndex(v []reflect.Value, args ...string) ([]reflect.Value, error)
 		return nil, fmt.Errorf("index is empty")
 	}
 
-	vValue := v[0]
+	vValue := elem(v[0])
 
 	switch vValue.Kind() {
 	case reflect.Slice, reflect.Array:
@@ -38,7 +51,17 @@ func OptionGetIndex(v []reflect.Value, args ...string) ([]reflect.Value, error)
 	case reflect.Map:
 		var retV []reflect.Value
 		for _, arg := range args {
-			retV = append(retV, vValue.MapIndex(reflect.ValueOf(arg)))
+			key, err := mapKey(vValue.Type().Key(), arg)
+			if err != nil {
+				return nil, fmt.Errorf("key %s for type %s; %w", arg, vValue.Type(), err)
+			}
+
+			value := vValue.MapIndex(key)
+			if strict && !value.IsValid() {
+				return nil, fmt.Errorf("key %s not found in %s", arg, vValue.Type())
+			}
+
+			retV = append(retV, value)
 		}
 
 		return retV, nil
@@ -47,36 +70,181 @@ func OptionGetIndex(v []reflect.Value, args ...string) ([]reflect.Value, error)
 	}
 }
 
-// OptionVariadic returns variadic value, value should be slice,array or map.
-// If map type it turns into slice of values.
-func OptionVariadic(v []reflect.Value, _ ...string) ([]reflect.Value, error) {
+// OptionDefault replaces nil values with the default like `default=30s`.
+//
+// Default is parsed to the parameter type when calling the function, arguments with
+// default option are not required to be in registry.
+func OptionDefault(v []reflect.Value, args ...string) ([]reflect.Value, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("default is empty")
+	}
+
+	defaultV := reflect.ValueOf(textValue(strings.Join(args, ",")))
+
+	if len(v) == 0 {
+		return []reflect.Value{defaultV}, nil
+	}
+
+	retV := make([]reflect.Value, len(v))
+	for i, value := range v {
+		if isNil(value) {
+			value = defaultV
+		}
+
+		retV[i] = value
+	}
+
+	return retV, nil
+}
+
+// isNil reports value is invalid or nil.
+func isNil(v reflect.Value) bool {
+	v = elem(v)
+
+	switch v.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return v.IsNil()
+	}
+
+	return false
+}
+
+// OptionField returns struct field values by dotted paths like `Server.Port`.
+//
+// Pointers, interfaces and embedded structs are followed.
+func OptionField(v []reflect.Value, args ...string) ([]reflect.Value, error) {
 	if len(v) == 0 {
 		return nil, fmt.Errorf("no value")
 	}
 
-	if len(v) > 1 {
-		return v, nil
+	if len(args) == 0 {
+		return nil, fmt.Errorf("field is empty")
 	}
 
-	// check v[0] slice or array or map
-	switch v[0].Kind() {
-	case reflect.Slice, reflect.Array, reflect.Map:
-	default:
-		return nil, fmt.Errorf("not related type for variadic")
+	retV := make([]reflect.Value, 0, len(args))
+	for _, arg := range args {
+		fieldV, err := fieldByPath(v[0], arg)
+		if err != nil {
+			return nil, err
+		}
+
+		retV = append(retV, fieldV)
 	}
 
-	// get slice
-	ret := make([]reflect.Value, v[0].Len())
+	return retV, nil
+}
+
+// OptionMethod calls methods without parameters and returns their values.
+//
+// Trailing error of the method is returned as error, methods with pointer receiver
+// are called on a copy when value is not a pointer.
+func OptionMethod(v []reflect.Value, args ...string) ([]reflect.Value, error) {
+	if len(v) == 0 {
+		return nil, fmt.Errorf("no value")
+	}
+
+	if len(args) == 0 {
+		return nil, fmt.Errorf("method is empty")
+	}
 
-	if v[0].Kind() == reflect.Map {
-		for i, key := range v[0].MapKeys() {
-			ret[i] = v[0].MapIndex(key)
+	var retV []reflect.Value
+	for _, arg := range args {
+		method, err := methodByName(v[0], arg)
+		if err != nil {
+			return nil, err
 		}
-	} else {
-		for i := 0; i < v[0].Len(); i++ {
-			ret[i] = v[0].Index(i)
+
+		returns, err := splitError(method.Call(nil))
+		if err != nil {
+			return nil, fmt.Errorf("method %s; %w", arg, err)
+		}
+
+		retV = append(retV, returns...)
+	}
+
+	return retV, nil
+}
+
+// fieldByPath returns field value with dotted path.
+func fieldByPath(v reflect.Value, path string) (reflect.Value, error) {
+	for _, name := range strings.Split(path, ".") {
+		v = indirect(v)
+		if !v.IsValid() {
+			return reflect.Value{}, fmt.Errorf("field %s of path %s is in nil value", name, path)
+		}
+
+		var err error
+		if v, err = fieldByName(v, name); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+
+	return v, nil
+}
+
+// fieldByName returns exported field of the struct value, embedded fields are searched.
+func fieldByName(v reflect.Value, name string) (reflect.Value, error) {
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("field %s not found in type %s, not a struct", name, v.Type())
+	}
+
+	field, ok := v.Type().FieldByName(name)
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("field %s not found in type %s", name, v.Type())
+	}
+
+	if !field.IsExported() {
+		return reflect.Value{}, fmt.Errorf("field %s is not exported in type %s", name, v.Type())
+	}
+
+	fieldV, err := v.FieldByIndexErr(field.Index)
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("field %s in type %s; %w", name, v.Type(), err)
+	}
+
+	return fieldV, nil
+}
+
+// methodByName returns method of the value, pointer receiver methods are also searched.
+func methodByName(v reflect.Value, name string) (reflect.Value, error) {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+
+	if !v.IsValid() {
+		return reflect.Value{}, fmt.Errorf("method %s called on nil value", name)
+	}
+
+	method := v.MethodByName(name)
+	if !method.IsValid() && v.Kind() != reflect.Pointer {
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+
+		method = ptr.MethodByName(name)
+	}
+
+	if !method.IsValid() {
+		return reflect.Value{}, fmt.Errorf("method %s not found in type %s", name, v.Type())
+	}
+
+	if method.Type().NumIn() != 0 {
+		return reflect.Value{}, fmt.Errorf("method %s of type %s has parameters", name, v.Type())
+	}
+
+	return method, nil
+}
+
+// indirect follows pointers and interfaces, it returns invalid value for nil.
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
 		}
+
+		v = v.Elem()
 	}
 
-	return ret, nil
+	return v
 }
@@ -1,6 +1,7 @@
 package call
 
 import (
+	"fmt"
 	"reflect"
 	"testing"
 )
@@ -83,6 +84,71 @@ func TestOptionGetIndex(t *testing.T) {
 			},
 			want: []reflect.Value{reflect.ValueOf("test"), reflect.ValueOf(123.123)},
 		},
+		{
+			name: "int map key",
+			args: args{
+				v:    []reflect.Value{reflect.ValueOf(map[int]string{1: "one", 2: "two"})},
+				args: []string{"2", "1"},
+			},
+			want: []reflect.Value{reflect.ValueOf("two"), reflect.ValueOf("one")},
+		},
+		{
+			name: "named string map key",
+			args: args{
+				v:    []reflect.Value{reflect.ValueOf(map[testKey]any{"a": 1})},
+				args: []string{"a"},
+			},
+			want: []reflect.Value{reflect.ValueOf(1)},
+		},
+		{
+			name: "bool map key",
+			args: args{
+				v:    []reflect.Value{reflect.ValueOf(map[bool]int{true: 1})},
+				args: []string{"true"},
+			},
+			want: []reflect.Value{reflect.ValueOf(1)},
+		},
+		{
+			name: "text unmarshaler map key",
+			args: args{
+				v:    []reflect.Value{reflect.ValueOf(map[testPoint]string{{X: 1, Y: 2}: "point"})},
+				args: []string{"1,2"},
+			},
+			want: []reflect.Value{reflect.ValueOf("point")},
+		},
+		{
+			name: "float and uint map key",
+			args: args{
+				v:    []reflect.Value{reflect.ValueOf(map[float64]uint{1.5: 3})},
+				args: []string{"1.5"},
+			},
+			want: []reflect.Value{reflect.ValueOf(uint(3))},
+		},
+		{
+			name: "wrong map key",
+			args: args{
+				v:    []reflect.Value{reflect.ValueOf(map[int]string{1: "one"})},
+				args: []string{"one"},
+			},
+			wantErr:    true,
+			wantErrStr: `key one for type map[int]string; strconv.ParseInt: parsing "one": invalid syntax`,
+		},
+		{
+			name: "missing map key",
+			args: args{
+				v:    []reflect.Value{reflect.ValueOf(map[string]int{"a": 1})},
+				args: []string{"b"},
+			},
+			want: []reflect.Value{{}},
+		},
+		{
+			name: "interface value",
+			args: args{
+				v:    []reflect.Value{reflect.ValueOf(map[string]any{"s": []any{1, 2}}).MapIndex(reflect.ValueOf("s"))},
+				args: []string{"1"},
+			},
+			want: []reflect.Value{reflect.ValueOf(2)},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -96,19 +162,152 @@ func TestOptionGetIndex(t *testing.T) {
 				return
 			}
 			if tt.wantErr == false {
+				if len(got) != len(tt.want) {
+					t.Fatalf("OptionGetIndex() = %v, want %v", got, tt.want)
+				}
 				for i := range got {
-					if !reflect.DeepEqual(got[i].Interface(), tt.want[i].Interface()) {
+					if got[i].IsValid() != tt.want[i].IsValid() {
 						t.Errorf("OptionGetIndex() = %v, want %v", got, tt.want)
+						continue
 					}
+					if got[i].IsValid() && !reflect.DeepEqual(got[i].Interface(), tt.want[i].Interface()) {
+						t.Errorf("OptionGetIndex() = %v, want %v", got, tt.want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestOptionGetIndexStrict(t *testing.T) {
+	tests := []struct {
+		name       string
+		v          any
+		args       []string
+		want       []any
+		wantErrStr string
+	}{
+		{
+			name: "found keys",
+			v:    map[int]string{1: "one", 2: "two"},
+			args: []string{"1", "2"},
+			want: []any{"one", "two"},
+		},
+		{
+			name:       "missing key",
+			v:          map[int]string{1: "one"},
+			args:       []string{"1", "3"},
+			wantErrStr: "key 3 not found in map[int]string",
+		},
+		{
+			name: "slice",
+			v:    []int{1, 2},
+			args: []string{"1"},
+			want: []any{2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := OptionGetIndexStrict([]reflect.Value{reflect.ValueOf(tt.v)}, tt.args...)
+			if err != nil {
+				if err.Error() != tt.wantErrStr {
+					t.Errorf("OptionGetIndexStrict() error = %v, wantErrStr %v", err, tt.wantErrStr)
+				}
+				return
+			}
+			if tt.wantErrStr != "" {
+				t.Fatalf("OptionGetIndexStrict() error = nil, wantErrStr %v", tt.wantErrStr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("OptionGetIndexStrict() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !reflect.DeepEqual(got[i].Interface(), tt.want[i]) {
+					t.Errorf("OptionGetIndexStrict() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestOptionDefault(t *testing.T) {
+	var nilMap map[string]int
+
+	tests := []struct {
+		name       string
+		v          []reflect.Value
+		args       []string
+		want       []any
+		wantErrStr string
+	}{
+		{
+			name: "no value",
+			args: []string{"30s"},
+			want: []any{textValue("30s")},
+		},
+		{
+			name: "invalid and nil values",
+			v:    []reflect.Value{{}, reflect.ValueOf(1), reflect.ValueOf(nilMap), reflect.ValueOf([]any{nil}).Index(0)},
+			args: []string{"x"},
+			want: []any{textValue("x"), 1, textValue("x"), textValue("x")},
+		},
+		{
+			name: "zero value kept",
+			v:    []reflect.Value{reflect.ValueOf(0)},
+			args: []string{"5"},
+			want: []any{0},
+		},
+		{
+			name: "joined args",
+			args: []string{"a", "b"},
+			want: []any{textValue("a,b")},
+		},
+		{
+			name:       "default is empty",
+			v:          []reflect.Value{{}},
+			wantErrStr: "default is empty",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := OptionDefault(tt.v, tt.args...)
+			if err != nil {
+				if err.Error() != tt.wantErrStr {
+					t.Errorf("OptionDefault() error = %v, wantErrStr %v", err, tt.wantErrStr)
+				}
+				return
+			}
+			if tt.wantErrStr != "" {
+				t.Fatalf("OptionDefault() error = nil, wantErrStr %v", tt.wantErrStr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("OptionDefault() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !reflect.DeepEqual(got[i].Interface(), tt.want[i]) {
+					t.Errorf("OptionDefault() = %v, want %v", got, tt.want)
 				}
 			}
 		})
 	}
 }
 
+type testKey string
+
+type testPoint struct {
+	X, Y int
+}
+
+func (p *testPoint) UnmarshalText(text []byte) error {
+	_, err := fmt.Sscanf(string(text), "%d,%d", &p.X, &p.Y)
+
+	return err
+}
+
 func TestOptionVariadic(t *testing.T) {
 	type args struct {
-		v []reflect.Value
+		v    []reflect.Value
+		args []string
 	}
 	tests := []struct {
 		name       string
@@ -149,10 +348,34 @@ func TestOptionVariadic(t *testing.T) {
 			},
 			want: []reflect.Value{reflect.ValueOf("test"), reflect.ValueOf(123), reflect.ValueOf(123.123)},
 		},
+		{
+			name: "map ordered by keys",
+			args: args{
+				v: []reflect.Value{reflect.ValueOf(map[int]string{10: "c", 2: "b", 1: "a"})},
+			},
+			want: []reflect.Value{reflect.ValueOf("a"), reflect.ValueOf("b"), reflect.ValueOf("c")},
+		},
+		{
+			name: "map pairs",
+			args: args{
+				v:    []reflect.Value{reflect.ValueOf(map[string]int{"y": 2, "x": 1})},
+				args: []string{"pairs"},
+			},
+			want: []reflect.Value{reflect.ValueOf("x"), reflect.ValueOf(1), reflect.ValueOf("y"), reflect.ValueOf(2)},
+		},
+		{
+			name: "unknown mode",
+			args: args{
+				v:    []reflect.Value{reflect.ValueOf(map[string]int{"x": 1})},
+				args: []string{"all"},
+			},
+			wantErr:    true,
+			wantErrStr: "unknown variadic mode all",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := OptionVariadic(tt.args.v)
+			got, err := OptionVariadic(tt.args.v, tt.args.args...)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("OptionVariadic() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -162,6 +385,9 @@ func TestOptionVariadic(t *testing.T) {
 				return
 			}
 			if tt.wantErr == false {
+				if len(got) != len(tt.want) {
+					t.Fatalf("OptionVariadic() = %v, want %v", got, tt.want)
+				}
 				for i := range got {
 					if !reflect.DeepEqual(got[i].Interface(), tt.want[i].Interface()) {
 						t.Errorf("OptionVariadic() = %v, want %v", got, tt.want)
@@ -171,3 +397,280 @@ func TestOptionVariadic(t *testing.T) {
 		})
 	}
 }
+
+type testServer struct {
+	Port int
+	host string
+}
+
+type testBase struct {
+	Name string
+}
+
+type testConfig struct {
+	*testBase
+	Server  testServer
+	Backup  *testServer
+	Timeout int
+}
+
+func (c testConfig) GetTimeout() int { return c.Timeout }
+
+func (c *testConfig) Address() (string, error) {
+	if c.Backup == nil {
+		return "", fmt.Errorf("no backup")
+	}
+
+	return fmt.Sprint(c.Backup.Port), nil
+}
+
+func (c testConfig) Pair() (int, int) { return c.Server.Port, c.Timeout }
+
+func (c testConfig) Set(int) {}
+
+func TestOptionField(t *testing.T) {
+	cfg := testConfig{
+		testBase: &testBase{Name: "base"},
+		Server:   testServer{Port: 8080},
+		Timeout:  30,
+	}
+
+	type args struct {
+		v    []reflect.Value
+		args []string
+	}
+	tests := []struct {
+		name       string
+		args       args
+		want       []reflect.Value
+		wantErr    bool
+		wantErrStr string
+	}{
+		{
+			name: "no value",
+			args: args{
+				args: []string{"Port"},
+			},
+			wantErr:    true,
+			wantErrStr: "no value",
+		},
+		{
+			name: "field is empty",
+			args: args{
+				v: []reflect.Value{reflect.ValueOf(cfg)},
+			},
+			wantErr:    true,
+			wantErrStr: "field is empty",
+		},
+		{
+			name: "nested fields",
+			args: args{
+				v:    []reflect.Value{reflect.ValueOf(&cfg)},
+				args: []string{"Server.Port", "Timeout"},
+			},
+			want: []reflect.Value{reflect.ValueOf(8080), reflect.ValueOf(30)},
+		},
+		{
+			name: "embedded pointer",
+			args: args{
+				v:    []reflect.Value{reflect.ValueOf(cfg)},
+				args: []string{"Name"},
+			},
+			want: []reflect.Value{reflect.ValueOf("base")},
+		},
+		{
+			name: "nil embedded pointer",
+			args: args{
+				v:    []reflect.Value{reflect.ValueOf(testConfig{})},
+				args: []string{"Name"},
+			},
+			wantErr:    true,
+			wantErrStr: "field Name in type call.testConfig; reflect: indirection through nil pointer to embedded struct field testBase",
+		},
+		{
+			name: "nil pointer in path",
+			args: args{
+				v:    []reflect.Value{reflect.ValueOf(cfg)},
+				args: []string{"Backup.Port"},
+			},
+			wantErr:    true,
+			wantErrStr: "field Port of path Backup.Port is in nil value",
+		},
+		{
+			name: "field not found",
+			args: args{
+				v:    []reflect.Value{reflect.ValueOf(cfg)},
+				args: []string{"Server.Host"},
+			},
+			wantErr:    true,
+			wantErrStr: "field Host not found in type call.testServer",
+		},
+		{
+			name: "not exported",
+			args: args{
+				v:    []reflect.Value{reflect.ValueOf(cfg)},
+				args: []string{"Server.host"},
+			},
+			wantErr:    true,
+			wantErrStr: "field host is not exported in type call.testServer",
+		},
+		{
+			name: "not a struct",
+			args: args{
+				v:    []reflect.Value{reflect.ValueOf(cfg)},
+				args: []string{"Timeout.Value"},
+			},
+			wantErr:    true,
+			wantErrStr: "field Value not found in type int, not a struct",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := OptionField(tt.args.v, tt.args.args...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("OptionField() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil && err.Error() != tt.wantErrStr {
+				t.Errorf("OptionField() error = %v, wantErrStr %v", err, tt.wantErrStr)
+				return
+			}
+			if tt.wantErr == false {
+				if len(got) != len(tt.want) {
+					t.Fatalf("OptionField() = %v, want %v", got, tt.want)
+				}
+				for i := range got {
+					if !reflect.DeepEqual(got[i].Interface(), tt.want[i].Interface()) {
+						t.Errorf("OptionField() = %v, want %v", got, tt.want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestOptionMethod(t *testing.T) {
+	cfg := testConfig{
+		Server:  testServer{Port: 8080},
+		Backup:  &testServer{Port: 9090},
+		Timeout: 30,
+	}
+
+	type args struct {
+		v    []reflect.Value
+		args []string
+	}
+	tests := []struct {
+		name       string
+		args       args
+		want       []reflect.Value
+		wantErr    bool
+		wantErrStr string
+	}{
+		{
+			name: "no value",
+			args: args{
+				args: []string{"GetTimeout"},
+			},
+			wantErr:    true,
+			wantErrStr: "no value",
+		},
+		{
+			name: "method is empty",
+			args: args{
+				v: []reflect.Value{reflect.ValueOf(cfg)},
+			},
+			wantErr:    true,
+			wantErrStr: "method is empty",
+		},
+		{
+			name: "value and pointer receiver",
+			args: args{
+				v:    []reflect.Value{reflect.ValueOf(cfg)},
+				args: []string{"GetTimeout", "Address"},
+			},
+			want: []reflect.Value{reflect.ValueOf(30), reflect.ValueOf("9090")},
+		},
+		{
+			name: "multiple returns",
+			args: args{
+				v:    []reflect.Value{reflect.ValueOf(&cfg)},
+				args: []string{"Pair"},
+			},
+			want: []reflect.Value{reflect.ValueOf(8080), reflect.ValueOf(30)},
+		},
+		{
+			name: "method error",
+			args: args{
+				v:    []reflect.Value{reflect.ValueOf(testConfig{})},
+				args: []string{"Address"},
+			},
+			wantErr:    true,
+			wantErrStr: "method Address; no backup",
+		},
+		{
+			name: "method not found",
+			args: args{
+				v:    []reflect.Value{reflect.ValueOf(cfg)},
+				args: []string{"Timeout"},
+			},
+			wantErr:    true,
+			wantErrStr: "method Timeout not found in type call.testConfig",
+		},
+		{
+			name: "method has parameters",
+			args: args{
+				v:    []reflect.Value{reflect.ValueOf(cfg)},
+				args: []string{"Set"},
+			},
+			wantErr:    true,
+			wantErrStr: "method Set of type call.testConfig has parameters",
+		},
+		{
+			name: "nil value",
+			args: args{
+				v:    []reflect.Value{reflect.ValueOf([]any{nil}).Index(0)},
+				args: []string{"Set"},
+			},
+			wantErr:    true,
+			wantErrStr: "method Set called on nil value",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := OptionMethod(tt.args.v, tt.args.args...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("OptionMethod() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil && err.Error() != tt.wantErrStr {
+				t.Errorf("OptionMethod() error = %v, wantErrStr %v", err, tt.wantErrStr)
+				return
+			}
+			if tt.wantErr == false {
+				if len(got) != len(tt.want) {
+					t.Fatalf("OptionMethod() = %v, want %v", got, tt.want)
+				}
+				for i := range got {
+					if !reflect.DeepEqual(got[i].Interface(), tt.want[i].Interface()) {
+						t.Errorf("OptionMethod() = %v, want %v", got, tt.want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestReg_IndexChained(t *testing.T) {
+	reg := NewReg().
+		AddArgument("cfg", map[string]any{"s": []any{1, 2}}).
+		AddFunction("get", func(v int) int { return v })
+
+	got, err := reg.CallWithArgs("get", "cfg:index=s;index=1")
+	if err != nil {
+		t.Fatalf("Reg.CallWithArgs() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, []any{2}) {
+		t.Errorf("Reg.CallWithArgs() = %v, want [2]", got)
+	}
+}
@@ -0,0 +1,239 @@
+package call
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// KeyCompare compares map keys, returns negative when a is before b, positive when a is after b.
+type KeyCompare func(a, b reflect.Value) int
+
+// Entry is a key and value of the map.
+type Entry struct {
+	Key   any
+	Value any
+}
+
+// MapOptions returns "...", "keys", "values", "entries" and "path" options which order map keys with cmp.
+//
+// Give them to NewReg to replace the default order.
+//
+//	reg := call.NewReg(call.MapOptions(myCompare)...)
+func MapOptions(cmp KeyCompare) []OptionFunc {
+	return []OptionFunc{
+		{Name: "...", Fn: variadic(cmp)},
+		{Name: "keys", Fn: mapKeysOption(cmp)},
+		{Name: "values", Fn: mapValuesOption(cmp)},
+		{Name: "entries", Fn: mapEntriesOption(cmp)},
+		{Name: "path", Fn: pathOption(cmp)},
+	}
+}
+
+// OptionVariadic returns variadic value, value should be slice, array or map.
+//
+// Map values are ordered by keys, `...=pairs` returns key and value one after another.
+func OptionVariadic(v []reflect.Value, args ...string) ([]reflect.Value, error) {
+	return variadic(CompareKeys)(v, args...)
+}
+
+// OptionKeys returns keys of the map in order.
+func OptionKeys(v []reflect.Value, args ...string) ([]reflect.Value, error) {
+	return mapKeysOption(CompareKeys)(v, args...)
+}
+
+// OptionValues returns values of the map ordered by keys.
+func OptionValues(v []reflect.Value, args ...string) ([]reflect.Value, error) {
+	return mapValuesOption(CompareKeys)(v, args...)
+}
+
+// OptionEntries returns Entry values of the map ordered by keys.
+func OptionEntries(v []reflect.Value, args ...string) ([]reflect.Value, error) {
+	return mapEntriesOption(CompareKeys)(v, args...)
+}
+
+func variadic(cmp KeyCompare) func([]reflect.Value, ...string) ([]reflect.Value, error) {
+	return func(v []reflect.Value, args ...string) ([]reflect.Value, error) {
+		if len(v) == 0 {
+			return nil, fmt.Errorf("no value")
+		}
+
+		pairs := false
+		for _, arg := range args {
+			if arg != "pairs" {
+				return nil, fmt.Errorf("unknown variadic mode %s", arg)
+			}
+
+			pairs = true
+		}
+
+		if len(v) > 1 {
+			return v, nil
+		}
+
+		value := elem(v[0])
+
+		// check value slice or array or map
+		switch value.Kind() {
+		case reflect.Slice, reflect.Array:
+		case reflect.Map:
+			keys := sortedMapKeys(value, cmp)
+
+			ret := make([]reflect.Value, 0, len(keys))
+			for _, key := range keys {
+				if pairs {
+					ret = append(ret, key)
+				}
+
+				ret = append(ret, value.MapIndex(key))
+			}
+
+			return ret, nil
+		default:
+			return nil, fmt.Errorf("not related type for variadic")
+		}
+
+		ret := make([]reflect.Value, value.Len())
+		for i := range ret {
+			ret[i] = value.Index(i)
+		}
+
+		return ret, nil
+	}
+}
+
+func mapKeysOption(cmp KeyCompare) func([]reflect.Value, ...string) ([]reflect.Value, error) {
+	return func(v []reflect.Value, _ ...string) ([]reflect.Value, error) {
+		value, err := mapValue(v, "keys")
+		if err != nil {
+			return nil, err
+		}
+
+		return sortedMapKeys(value, cmp), nil
+	}
+}
+
+func mapValuesOption(cmp KeyCompare) func([]reflect.Value, ...string) ([]reflect.Value, error) {
+	return func(v []reflect.Value, _ ...string) ([]reflect.Value, error) {
+		value, err := mapValue(v, "values")
+		if err != nil {
+			return nil, err
+		}
+
+		keys := sortedMapKeys(value, cmp)
+
+		ret := make([]reflect.Value, len(keys))
+		for i, key := range keys {
+			ret[i] = value.MapIndex(key)
+		}
+
+		return ret, nil
+	}
+}
+
+func mapEntriesOption(cmp KeyCompare) func([]reflect.Value, ...string) ([]reflect.Value, error) {
+	return func(v []reflect.Value, _ ...string) ([]reflect.Value, error) {
+		value, err := mapValue(v, "entries")
+		if err != nil {
+			return nil, err
+		}
+
+		keys := sortedMapKeys(value, cmp)
+
+		ret := make([]reflect.Value, len(keys))
+		for i, key := range keys {
+			ret[i] = reflect.ValueOf(Entry{
+				Key:   key.Interface(),
+				Value: value.MapIndex(key).Interface(),
+			})
+		}
+
+		return ret, nil
+	}
+}
+
+// mapValue returns the map value of the option.
+func mapValue(v []reflect.Value, option string) (reflect.Value, error) {
+	if len(v) == 0 {
+		return reflect.Value{}, fmt.Errorf("no value")
+	}
+
+	value := elem(v[0])
+	if value.Kind() != reflect.Map {
+		return reflect.Value{}, fmt.Errorf("not related type for %s", option)
+	}
+
+	return value, nil
+}
+
+// elem returns concrete value of the interface.
+func elem(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Interface {
+		return v.Elem()
+	}
+
+	return v
+}
+
+// sortedMapKeys returns keys of the map ordered with cmp.
+func sortedMapKeys(v reflect.Value, cmp KeyCompare) []reflect.Value {
+	keys := v.MapKeys()
+	sort.SliceStable(keys, func(i, j int) bool {
+		return cmp(keys[i], keys[j]) < 0
+	})
+
+	return keys
+}
+
+// CompareKeys is the default order of map keys.
+//
+// Numbers, strings and booleans are compared by value, other types by their string form.
+func CompareKeys(a, b reflect.Value) int {
+	a, b = elem(a), elem(b)
+
+	if a.IsValid() && b.IsValid() && a.Kind() == b.Kind() {
+		switch a.Kind() {
+		case reflect.String:
+			return strings.Compare(a.String(), b.String())
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return compareOrdered(a.Int(), b.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return compareOrdered(a.Uint(), b.Uint())
+		case reflect.Float32, reflect.Float64:
+			return compareOrdered(a.Float(), b.Float())
+		case reflect.Bool:
+			return compareOrdered(boolToInt(a.Bool()), boolToInt(b.Bool()))
+		}
+	}
+
+	return strings.Compare(fmt.Sprint(valueInterface(a)), fmt.Sprint(valueInterface(b)))
+}
+
+func compareOrdered[T int64 | uint64 | float64 | int](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+// valueInterface returns interface of the value, nil for invalid value.
+func valueInterface(v reflect.Value) any {
+	if !v.IsValid() {
+		return nil
+	}
+
+	return v.Interface()
+}
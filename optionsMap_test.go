@@ -0,0 +1,118 @@
+package call
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMapOptions(t *testing.T) {
+	m := map[string]int{"b": 2, "c": 3, "a": 1}
+
+	tests := []struct {
+		name       string
+		fn         func([]reflect.Value, ...string) ([]reflect.Value, error)
+		v          []reflect.Value
+		want       []any
+		wantErrStr string
+	}{
+		{
+			name: "keys",
+			fn:   OptionKeys,
+			v:    []reflect.Value{reflect.ValueOf(m)},
+			want: []any{"a", "b", "c"},
+		},
+		{
+			name: "values",
+			fn:   OptionValues,
+			v:    []reflect.Value{reflect.ValueOf(m)},
+			want: []any{1, 2, 3},
+		},
+		{
+			name: "entries",
+			fn:   OptionEntries,
+			v:    []reflect.Value{reflect.ValueOf(map[bool]string{true: "yes", false: "no"})},
+			want: []any{Entry{Key: false, Value: "no"}, Entry{Key: true, Value: "yes"}},
+		},
+		{
+			name: "interface map",
+			fn:   OptionKeys,
+			v:    []reflect.Value{reflect.ValueOf([]any{map[float64]int{2.5: 1, -1: 2}}).Index(0)},
+			want: []any{-1.0, 2.5},
+		},
+		{
+			name: "mixed key types",
+			fn:   OptionKeys,
+			v:    []reflect.Value{reflect.ValueOf(map[any]int{"b": 1, 2: 2, "a": 3})},
+			want: []any{2, "a", "b"},
+		},
+		{
+			name:       "no value",
+			fn:         OptionValues,
+			wantErrStr: "no value",
+		},
+		{
+			name:       "not a map",
+			fn:         OptionEntries,
+			v:          []reflect.Value{reflect.ValueOf([]int{1})},
+			wantErrStr: "not related type for entries",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.fn(tt.v)
+			if err != nil {
+				if err.Error() != tt.wantErrStr {
+					t.Errorf("error = %v, wantErrStr %v", err, tt.wantErrStr)
+				}
+				return
+			}
+			if tt.wantErrStr != "" {
+				t.Fatalf("error = nil, wantErrStr %v", tt.wantErrStr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !reflect.DeepEqual(got[i].Interface(), tt.want[i]) {
+					t.Errorf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestMapOptions_compare(t *testing.T) {
+	desc := func(a, b reflect.Value) int {
+		return -CompareKeys(a, b)
+	}
+
+	reg := NewReg(MapOptions(desc)...).
+		AddArgument("m", map[string]string{"a": "x", "b": "y", "c": "z"}).
+		AddFunction("join", func(v ...string) string {
+			return strings.Join(v, ",")
+		})
+
+	tests := []struct {
+		arg  string
+		want string
+	}{
+		{arg: "m:...", want: "z,y,x"},
+		{arg: "m:...=pairs", want: "c,z,b,y,a,x"},
+		{arg: "m:keys", want: "c,b,a"},
+		{arg: "m:values", want: "z,y,x"},
+		{arg: "m:path=*", want: "z,y,x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			returns, err := reg.CallWithArgs("join", tt.arg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if returns[0] != tt.want {
+				t.Errorf("join = %v, want %v", returns[0], tt.want)
+			}
+		})
+	}
+}
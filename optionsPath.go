@@ -0,0 +1,274 @@
+package call
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// pathKind is kind of the path segment.
+type pathKind int
+
+const (
+	// pathKey is map key or struct field.
+	pathKey pathKind = iota
+	// pathIndex is slice or array index, negative index counts from end.
+	pathIndex
+	// pathWildcard is all values of the map, slice, array or struct.
+	pathWildcard
+)
+
+type pathSegment struct {
+	kind  pathKind
+	key   string
+	index int
+}
+
+// OptionPath returns values with JSONPath like expressions, like `$.servers[0].ports[*]`.
+//
+// Path starts with optional `$` and supports `.key`, `['key']`, `[index]`, `[*]` and `.*`.
+// Keys are map keys or struct fields, map keys are converted to the key type of the map.
+// Wildcards return multiple values which can be used in variadic functions, map values
+// are ordered by keys.
+func OptionPath(v []reflect.Value, args ...string) ([]reflect.Value, error) {
+	return pathOption(CompareKeys)(v, args...)
+}
+
+// pathOption returns path option which orders map values of wildcards with cmp.
+func pathOption(cmp KeyCompare) func([]reflect.Value, ...string) ([]reflect.Value, error) {
+	return func(v []reflect.Value, args ...string) ([]reflect.Value, error) {
+		return optionPath(v, cmp, args...)
+	}
+}
+
+func optionPath(v []reflect.Value, cmp KeyCompare, args ...string) ([]reflect.Value, error) {
+	if len(v) == 0 {
+		return nil, fmt.Errorf("no value")
+	}
+
+	if len(args) == 0 {
+		return nil, fmt.Errorf("path is empty")
+	}
+
+	var retV []reflect.Value
+	for _, arg := range args {
+		segments, err := parsePath(arg)
+		if err != nil {
+			return nil, fmt.Errorf("path %s; %w", arg, err)
+		}
+
+		values, err := evalPath(v[0], segments, cmp)
+		if err != nil {
+			return nil, fmt.Errorf("path %s; %w", arg, err)
+		}
+
+		retV = append(retV, values...)
+	}
+
+	return retV, nil
+}
+
+// parsePath parses path to segments.
+func parsePath(path string) ([]pathSegment, error) {
+	start := 0
+	if strings.HasPrefix(path, "$") {
+		start = 1
+	}
+
+	var segments []pathSegment
+
+	for i := start; i < len(path); {
+		switch {
+		case path[i] == '.' || i == start && path[i] != '[':
+			// first key can be written without dot
+			if path[i] == '.' {
+				i++
+			}
+
+			end := i
+			for end < len(path) && path[end] != '.' && path[end] != '[' {
+				end++
+			}
+
+			key := path[i:end]
+			if key == "" {
+				return nil, fmt.Errorf("empty key at %d", i)
+			}
+
+			if key == "*" {
+				segments = append(segments, pathSegment{kind: pathWildcard})
+			} else {
+				segments = append(segments, pathSegment{kind: pathKey, key: key})
+			}
+
+			i = end
+		case path[i] == '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("missing ] at %d", i)
+			}
+
+			segment, err := parseBracket(path[i+1 : i+end])
+			if err != nil {
+				return nil, fmt.Errorf("%w at %d", err, i)
+			}
+
+			segments = append(segments, segment)
+			i += end + 1
+		default:
+			return nil, fmt.Errorf("unexpected %q at %d", path[i], i)
+		}
+	}
+
+	return segments, nil
+}
+
+// parseBracket parses content of the brackets.
+func parseBracket(s string) (pathSegment, error) {
+	if s == "*" {
+		return pathSegment{kind: pathWildcard}, nil
+	}
+
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		return pathSegment{kind: pathKey, key: s[1 : len(s)-1]}, nil
+	}
+
+	index, err := strconv.Atoi(s)
+	if err != nil {
+		return pathSegment{}, fmt.Errorf("index %q is not a number", s)
+	}
+
+	return pathSegment{kind: pathIndex, key: s, index: index}, nil
+}
+
+// evalPath returns values of the path segments.
+func evalPath(v reflect.Value, segments []pathSegment, cmp KeyCompare) ([]reflect.Value, error) {
+	values := []reflect.Value{v}
+
+	for _, segment := range segments {
+		next := make([]reflect.Value, 0, len(values))
+
+		for _, value := range values {
+			value = indirect(value)
+			if !value.IsValid() {
+				return nil, fmt.Errorf("nil value for %s", segment)
+			}
+
+			switch segment.kind {
+			case pathKey:
+				keyV, err := pathKeyValue(value, segment.key)
+				if err != nil {
+					return nil, err
+				}
+
+				next = append(next, keyV)
+			case pathIndex:
+				indexV, err := pathIndexValue(value, segment)
+				if err != nil {
+					return nil, err
+				}
+
+				next = append(next, indexV)
+			case pathWildcard:
+				all, err := pathAllValues(value, cmp)
+				if err != nil {
+					return nil, err
+				}
+
+				next = append(next, all...)
+			}
+		}
+
+		values = next
+	}
+
+	return values, nil
+}
+
+func (s pathSegment) String() string {
+	switch s.kind {
+	case pathIndex:
+		return "[" + s.key + "]"
+	case pathWildcard:
+		return "[*]"
+	default:
+		return "." + s.key
+	}
+}
+
+func pathKeyValue(v reflect.Value, key string) (reflect.Value, error) {
+	if v.Kind() == reflect.Struct {
+		return fieldByName(v, key)
+	}
+
+	if v.Kind() != reflect.Map {
+		return reflect.Value{}, fmt.Errorf("key %s not found in type %s", key, v.Type())
+	}
+
+	keyV, err := mapKey(v.Type().Key(), key)
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("key %s for type %s; %w", key, v.Type(), err)
+	}
+
+	value := v.MapIndex(keyV)
+	if !value.IsValid() {
+		return reflect.Value{}, fmt.Errorf("key %s not found in %s", key, v.Type())
+	}
+
+	return value, nil
+}
+
+func pathIndexValue(v reflect.Value, segment pathSegment) (reflect.Value, error) {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+	case reflect.Map:
+		return pathKeyValue(v, segment.key)
+	default:
+		return reflect.Value{}, fmt.Errorf("index %d not found in type %s", segment.index, v.Type())
+	}
+
+	index := segment.index
+	if index < 0 {
+		index += v.Len()
+	}
+
+	if index < 0 || index >= v.Len() {
+		return reflect.Value{}, fmt.Errorf("index %d out of range with length %d", segment.index, v.Len())
+	}
+
+	return v.Index(index), nil
+}
+
+// pathAllValues returns all values of the map, slice, array or exported fields of struct.
+func pathAllValues(v reflect.Value, cmp KeyCompare) ([]reflect.Value, error) {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		values := make([]reflect.Value, v.Len())
+		for i := range values {
+			values[i] = v.Index(i)
+		}
+
+		return values, nil
+	case reflect.Map:
+		keys := sortedMapKeys(v, cmp)
+
+		values := make([]reflect.Value, len(keys))
+		for i, key := range keys {
+			values[i] = v.MapIndex(key)
+		}
+
+		return values, nil
+	case reflect.Struct:
+		var values []reflect.Value
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				values = append(values, v.Field(i))
+			}
+		}
+
+		return values, nil
+	default:
+		return nil, fmt.Errorf("wildcard not supported for type %s", v.Type())
+	}
+}
@@ -0,0 +1,213 @@
+package call
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestOptionPath(t *testing.T) {
+	var doc any
+	if err := json.Unmarshal([]byte(`{
+		"servers": [
+			{"host": "a", "ports": [80, 443]},
+			{"host": "b", "ports": [8080]}
+		],
+		"tags": {"z": "last", "a": "first"}
+	}`), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := testConfig{
+		testBase: &testBase{Name: "base"},
+		Server:   testServer{Port: 8080},
+		Timeout:  30,
+	}
+
+	type args struct {
+		v    []reflect.Value
+		args []string
+	}
+	tests := []struct {
+		name       string
+		args       args
+		want       []reflect.Value
+		wantErr    bool
+		wantErrStr string
+	}{
+		{
+			name: "no value",
+			args: args{
+				args: []string{"$.servers"},
+			},
+			wantErr:    true,
+			wantErrStr: "no value",
+		},
+		{
+			name: "path is empty",
+			args: args{
+				v: []reflect.Value{reflect.ValueOf(doc)},
+			},
+			wantErr:    true,
+			wantErrStr: "path is empty",
+		},
+		{
+			name: "single value",
+			args: args{
+				v:    []reflect.Value{reflect.ValueOf(doc)},
+				args: []string{"$.servers[1].host"},
+			},
+			want: []reflect.Value{reflect.ValueOf("b")},
+		},
+		{
+			name: "wildcard",
+			args: args{
+				v:    []reflect.Value{reflect.ValueOf(doc)},
+				args: []string{"$.servers[0].ports[*]"},
+			},
+			want: []reflect.Value{reflect.ValueOf(80.0), reflect.ValueOf(443.0)},
+		},
+		{
+			name: "nested wildcard",
+			args: args{
+				v:    []reflect.Value{reflect.ValueOf(doc)},
+				args: []string{"$.servers[*].host"},
+			},
+			want: []reflect.Value{reflect.ValueOf("a"), reflect.ValueOf("b")},
+		},
+		{
+			name: "map wildcard sorted by key",
+			args: args{
+				v:    []reflect.Value{reflect.ValueOf(doc)},
+				args: []string{"tags.*"},
+			},
+			want: []reflect.Value{reflect.ValueOf("first"), reflect.ValueOf("last")},
+		},
+		{
+			name: "negative index and quoted key",
+			args: args{
+				v:    []reflect.Value{reflect.ValueOf(doc)},
+				args: []string{`$['servers'][-1]["host"]`},
+			},
+			want: []reflect.Value{reflect.ValueOf("b")},
+		},
+		{
+			name: "multiple paths",
+			args: args{
+				v:    []reflect.Value{reflect.ValueOf(doc)},
+				args: []string{"$.servers[0].host", "$.tags.z"},
+			},
+			want: []reflect.Value{reflect.ValueOf("a"), reflect.ValueOf("last")},
+		},
+		{
+			name: "struct fields",
+			args: args{
+				v:    []reflect.Value{reflect.ValueOf(&cfg)},
+				args: []string{"$.Server.Port", "$.Name"},
+			},
+			want: []reflect.Value{reflect.ValueOf(8080), reflect.ValueOf("base")},
+		},
+		{
+			name: "typed map key",
+			args: args{
+				v:    []reflect.Value{reflect.ValueOf(map[int][]string{1: {"x", "y"}})},
+				args: []string{"$[1][1]"},
+			},
+			want: []reflect.Value{reflect.ValueOf("y")},
+		},
+		{
+			name: "missing key",
+			args: args{
+				v:    []reflect.Value{reflect.ValueOf(doc)},
+				args: []string{"$.servers[0].user"},
+			},
+			wantErr:    true,
+			wantErrStr: "path $.servers[0].user; key user not found in map[string]interface {}",
+		},
+		{
+			name: "index out of range",
+			args: args{
+				v:    []reflect.Value{reflect.ValueOf(doc)},
+				args: []string{"$.servers[2]"},
+			},
+			wantErr:    true,
+			wantErrStr: "path $.servers[2]; index 2 out of range with length 2",
+		},
+		{
+			name: "index not a number",
+			args: args{
+				v:    []reflect.Value{reflect.ValueOf(doc)},
+				args: []string{"$.servers[x]"},
+			},
+			wantErr:    true,
+			wantErrStr: `path $.servers[x]; index "x" is not a number at 9`,
+		},
+		{
+			name: "missing bracket",
+			args: args{
+				v:    []reflect.Value{reflect.ValueOf(doc)},
+				args: []string{"$.servers[0"},
+			},
+			wantErr:    true,
+			wantErrStr: "path $.servers[0; missing ] at 9",
+		},
+		{
+			name: "wildcard not supported",
+			args: args{
+				v:    []reflect.Value{reflect.ValueOf(doc)},
+				args: []string{"$.servers[0].host[*]"},
+			},
+			wantErr:    true,
+			wantErrStr: "path $.servers[0].host[*]; wildcard not supported for type string",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := OptionPath(tt.args.v, tt.args.args...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("OptionPath() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil && err.Error() != tt.wantErrStr {
+				t.Errorf("OptionPath() error = %v, wantErrStr %v", err, tt.wantErrStr)
+				return
+			}
+			if tt.wantErr == false {
+				if len(got) != len(tt.want) {
+					t.Fatalf("OptionPath() = %v, want %v", got, tt.want)
+				}
+				for i := range got {
+					if !reflect.DeepEqual(got[i].Interface(), tt.want[i].Interface()) {
+						t.Errorf("OptionPath() = %v, want %v", got, tt.want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestOptionPath_variadic(t *testing.T) {
+	reg := NewReg().
+		AddArgument("config", map[string]any{
+			"servers": []any{
+				map[string]any{"ports": []int{80, 443}},
+			},
+		}).
+		AddFunction("sum", func(v ...int) int {
+			total := 0
+			for _, n := range v {
+				total += n
+			}
+
+			return total
+		})
+
+	returns, err := reg.CallWithArgs("sum", "config:path=$.servers[0].ports[*]")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if returns[0] != 523 {
+		t.Errorf("sum = %v, want 523", returns[0])
+	}
+}
@@ -0,0 +1,218 @@
+package call
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// Collection options work on a single slice or array value and return a new slice,
+// when there are multiple values (like after `...`) they work on the values.
+
+// OptionSlice returns elements between start and end like `slice=1,5`, end is optional.
+//
+// Negative positions count from the end.
+func OptionSlice(v []reflect.Value, args ...string) ([]reflect.Value, error) {
+	if len(args) == 0 || len(args) > 2 {
+		return nil, fmt.Errorf("slice needs start and optional end")
+	}
+
+	return collect(v, "slice", func(length int) ([]int, error) {
+		start, err := slicePosition(args[0], length)
+		if err != nil {
+			return nil, fmt.Errorf("start %w", err)
+		}
+
+		end := length
+		if len(args) == 2 && args[1] != "" {
+			if end, err = slicePosition(args[1], length); err != nil {
+				return nil, fmt.Errorf("end %w", err)
+			}
+		}
+
+		if start > end {
+			return nil, fmt.Errorf("start %d is after end %d", start, end)
+		}
+
+		indexes := make([]int, 0, end-start)
+		for i := start; i < end; i++ {
+			indexes = append(indexes, i)
+		}
+
+		return indexes, nil
+	})
+}
+
+// OptionReverse returns elements in reverse order.
+func OptionReverse(v []reflect.Value, _ ...string) ([]reflect.Value, error) {
+	return collect(v, "reverse", func(length int) ([]int, error) {
+		indexes := make([]int, length)
+		for i := range indexes {
+			indexes[i] = length - 1 - i
+		}
+
+		return indexes, nil
+	})
+}
+
+// OptionFilter returns elements matching the filter, `filter=nonzero` removes zero values.
+func OptionFilter(v []reflect.Value, args ...string) ([]reflect.Value, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("filter needs one name")
+	}
+
+	var keep func(reflect.Value) bool
+
+	switch args[0] {
+	case "nonzero":
+		keep = func(e reflect.Value) bool {
+			e = elem(e)
+
+			return e.IsValid() && !e.IsZero()
+		}
+	default:
+		return nil, fmt.Errorf("unknown filter %s", args[0])
+	}
+
+	values, single, err := collection(v, "filter")
+	if err != nil {
+		return nil, err
+	}
+
+	indexes := make([]int, 0, len(values))
+	for i, e := range values {
+		if keep(e) {
+			indexes = append(indexes, i)
+		}
+	}
+
+	return pick(v, values, single, indexes), nil
+}
+
+// OptionFirst returns the first element.
+func OptionFirst(v []reflect.Value, _ ...string) ([]reflect.Value, error) {
+	values, _, err := collection(v, "first")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(values) == 0 {
+		return nil, fmt.Errorf("first of empty value")
+	}
+
+	return values[:1], nil
+}
+
+// OptionLast returns the last element.
+func OptionLast(v []reflect.Value, _ ...string) ([]reflect.Value, error) {
+	values, _, err := collection(v, "last")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(values) == 0 {
+		return nil, fmt.Errorf("last of empty value")
+	}
+
+	return values[len(values)-1:], nil
+}
+
+// OptionLen returns length of slice, array, map or string as int, multiple values return their count.
+func OptionLen(v []reflect.Value, _ ...string) ([]reflect.Value, error) {
+	if len(v) == 0 {
+		return nil, fmt.Errorf("no value")
+	}
+
+	if len(v) > 1 {
+		return []reflect.Value{reflect.ValueOf(len(v))}, nil
+	}
+
+	value := elem(v[0])
+
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.String, reflect.Chan:
+		return []reflect.Value{reflect.ValueOf(value.Len())}, nil
+	default:
+		return nil, fmt.Errorf("not related type for len")
+	}
+}
+
+// collection returns elements of the single slice or array, otherwise the values.
+func collection(v []reflect.Value, option string) ([]reflect.Value, bool, error) {
+	if len(v) == 0 {
+		return nil, false, fmt.Errorf("no value")
+	}
+
+	if len(v) > 1 {
+		return v, false, nil
+	}
+
+	value := elem(v[0])
+
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+	default:
+		return nil, false, fmt.Errorf("not related type for %s", option)
+	}
+
+	values := make([]reflect.Value, value.Len())
+	for i := range values {
+		values[i] = value.Index(i)
+	}
+
+	return values, true, nil
+}
+
+// collect picks elements with indexes returned by fn.
+func collect(v []reflect.Value, option string, fn func(length int) ([]int, error)) ([]reflect.Value, error) {
+	values, single, err := collection(v, option)
+	if err != nil {
+		return nil, err
+	}
+
+	indexes, err := fn(len(values))
+	if err != nil {
+		return nil, err
+	}
+
+	return pick(v, values, single, indexes), nil
+}
+
+// pick returns elements with indexes, single slice or array returns new slice value.
+func pick(v, values []reflect.Value, single bool, indexes []int) []reflect.Value {
+	if !single {
+		ret := make([]reflect.Value, len(indexes))
+		for i, index := range indexes {
+			ret[i] = values[index]
+		}
+
+		return ret
+	}
+
+	sliceType := reflect.SliceOf(elem(v[0]).Type().Elem())
+
+	ret := reflect.MakeSlice(sliceType, len(indexes), len(indexes))
+	for i, index := range indexes {
+		ret.Index(i).Set(values[index])
+	}
+
+	return []reflect.Value{ret}
+}
+
+// slicePosition parses position of the slice, negative positions count from the end.
+func slicePosition(s string, length int) (int, error) {
+	pos, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("is not a number; %w", err)
+	}
+
+	if pos < 0 {
+		pos += length
+	}
+
+	if pos < 0 || pos > length {
+		return 0, fmt.Errorf("%s out of range with length %d", s, length)
+	}
+
+	return pos, nil
+}
@@ -0,0 +1,237 @@
+package call
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSliceOptions(t *testing.T) {
+	list := []int{1, 0, 2, 0, 3}
+	values := []reflect.Value{reflect.ValueOf("a"), reflect.ValueOf(""), reflect.ValueOf("c")}
+
+	type args struct {
+		v    []reflect.Value
+		args []string
+	}
+	tests := []struct {
+		name       string
+		fn         func([]reflect.Value, ...string) ([]reflect.Value, error)
+		args       args
+		want       []any
+		wantErr    bool
+		wantErrStr string
+	}{
+		{
+			name: "slice",
+			fn:   OptionSlice,
+			args: args{v: []reflect.Value{reflect.ValueOf(list)}, args: []string{"1", "3"}},
+			want: []any{[]int{0, 2}},
+		},
+		{
+			name: "slice without end",
+			fn:   OptionSlice,
+			args: args{v: []reflect.Value{reflect.ValueOf(list)}, args: []string{"-2"}},
+			want: []any{[]int{0, 3}},
+		},
+		{
+			name: "slice array",
+			fn:   OptionSlice,
+			args: args{v: []reflect.Value{reflect.ValueOf([3]string{"x", "y", "z"})}, args: []string{"0", "2"}},
+			want: []any{[]string{"x", "y"}},
+		},
+		{
+			name: "slice values",
+			fn:   OptionSlice,
+			args: args{v: values, args: []string{"1"}},
+			want: []any{"", "c"},
+		},
+		{
+			name:       "slice out of range",
+			fn:         OptionSlice,
+			args:       args{v: []reflect.Value{reflect.ValueOf(list)}, args: []string{"1", "6"}},
+			wantErr:    true,
+			wantErrStr: "end 6 out of range with length 5",
+		},
+		{
+			name:       "slice start after end",
+			fn:         OptionSlice,
+			args:       args{v: []reflect.Value{reflect.ValueOf(list)}, args: []string{"3", "1"}},
+			wantErr:    true,
+			wantErrStr: "start 3 is after end 1",
+		},
+		{
+			name:       "slice not a number",
+			fn:         OptionSlice,
+			args:       args{v: []reflect.Value{reflect.ValueOf(list)}, args: []string{"a"}},
+			wantErr:    true,
+			wantErrStr: `start is not a number; strconv.Atoi: parsing "a": invalid syntax`,
+		},
+		{
+			name:       "slice without args",
+			fn:         OptionSlice,
+			args:       args{v: []reflect.Value{reflect.ValueOf(list)}},
+			wantErr:    true,
+			wantErrStr: "slice needs start and optional end",
+		},
+		{
+			name:       "slice not related type",
+			fn:         OptionSlice,
+			args:       args{v: []reflect.Value{reflect.ValueOf(1)}, args: []string{"0"}},
+			wantErr:    true,
+			wantErrStr: "not related type for slice",
+		},
+		{
+			name: "reverse",
+			fn:   OptionReverse,
+			args: args{v: []reflect.Value{reflect.ValueOf([]any{1, "b", 3.0})}},
+			want: []any{[]any{3.0, "b", 1}},
+		},
+		{
+			name: "reverse values",
+			fn:   OptionReverse,
+			args: args{v: values},
+			want: []any{"c", "", "a"},
+		},
+		{
+			name: "first",
+			fn:   OptionFirst,
+			args: args{v: []reflect.Value{reflect.ValueOf(list)}},
+			want: []any{1},
+		},
+		{
+			name:       "first of empty",
+			fn:         OptionFirst,
+			args:       args{v: []reflect.Value{reflect.ValueOf([]int{})}},
+			wantErr:    true,
+			wantErrStr: "first of empty value",
+		},
+		{
+			name: "last",
+			fn:   OptionLast,
+			args: args{v: values},
+			want: []any{"c"},
+		},
+		{
+			name:       "last no value",
+			fn:         OptionLast,
+			wantErr:    true,
+			wantErrStr: "no value",
+		},
+		{
+			name: "len",
+			fn:   OptionLen,
+			args: args{v: []reflect.Value{reflect.ValueOf(map[string]int{"a": 1})}},
+			want: []any{1},
+		},
+		{
+			name: "len values",
+			fn:   OptionLen,
+			args: args{v: values},
+			want: []any{3},
+		},
+		{
+			name:       "len not related type",
+			fn:         OptionLen,
+			args:       args{v: []reflect.Value{reflect.ValueOf(1)}},
+			wantErr:    true,
+			wantErrStr: "not related type for len",
+		},
+		{
+			name: "filter nonzero",
+			fn:   OptionFilter,
+			args: args{v: []reflect.Value{reflect.ValueOf(list)}, args: []string{"nonzero"}},
+			want: []any{[]int{1, 2, 3}},
+		},
+		{
+			name: "filter nonzero interfaces",
+			fn:   OptionFilter,
+			args: args{v: []reflect.Value{reflect.ValueOf([]any{nil, 0, "x"})}, args: []string{"nonzero"}},
+			want: []any{[]any{"x"}},
+		},
+		{
+			name: "filter nonzero values",
+			fn:   OptionFilter,
+			args: args{v: values, args: []string{"nonzero"}},
+			want: []any{"a", "c"},
+		},
+		{
+			name:       "unknown filter",
+			fn:         OptionFilter,
+			args:       args{v: values, args: []string{"even"}},
+			wantErr:    true,
+			wantErrStr: "unknown filter even",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.fn(tt.args.v, tt.args.args...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil && err.Error() != tt.wantErrStr {
+				t.Errorf("error = %v, wantErrStr %v", err, tt.wantErrStr)
+				return
+			}
+			if tt.wantErr == false {
+				if len(got) != len(tt.want) {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+				for i := range got {
+					if !reflect.DeepEqual(got[i].Interface(), tt.want[i]) {
+						t.Errorf("got %v, want %v", got, tt.want)
+					}
+				}
+			}
+		})
+	}
+
+	t.Run("original not changed", func(t *testing.T) {
+		if _, err := OptionReverse([]reflect.Value{reflect.ValueOf(list)}); err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(list, []int{1, 0, 2, 0, 3}) {
+			t.Errorf("list changed to %v", list)
+		}
+	})
+}
+
+func TestSliceOptions_call(t *testing.T) {
+	reg := NewReg().
+		AddArgument("list", []int{5, 0, 4, 3, 0}).
+		AddFunction("sum", func(v ...int) int {
+			total := 0
+			for _, n := range v {
+				total += n
+			}
+
+			return total
+		}).
+		AddFunction("count", func(v []int) int { return len(v) })
+
+	tests := []struct {
+		fn   string
+		args []string
+		want int
+	}{
+		{fn: "sum", args: []string{"list:slice=1,4;..."}, want: 7},
+		{fn: "sum", args: []string{"list:reverse;first"}, want: 0},
+		{fn: "sum", args: []string{"list:filter=nonzero;last"}, want: 3},
+		{fn: "sum", args: []string{"list:len"}, want: 5},
+		{fn: "sum", args: []string{"list:...;filter=nonzero;len"}, want: 3},
+		{fn: "count", args: []string{"list:filter=nonzero"}, want: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.args[0], func(t *testing.T) {
+			returns, err := reg.CallWithArgs(tt.fn, tt.args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if returns[0] != tt.want {
+				t.Errorf("%s = %v, want %v", tt.fn, returns[0], tt.want)
+			}
+		})
+	}
+}
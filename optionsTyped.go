@@ -0,0 +1,187 @@
+package call
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// NewOptionFunc returns option from a typed function.
+//
+// First parameter gets values of the argument, slice parameter gets all values or elements
+// of the single slice value and others get exactly one value. Rest of the parameters get option arguments parsed to
+// their types, variadic parameter gets remaining arguments. Function can start with
+// context.Context parameter to get context of the call.
+//
+// Return value is the new value of the argument, trailing error is returned as error.
+// Functions returning only error keep values as they are.
+//
+//	call.NewOptionFunc("add", func(v []int, n int) ([]int, error) { ... })
+//
+// Argument must be a function, otherwise it will panic.
+func NewOptionFunc(name string, fn any) OptionFunc {
+	fnV := reflect.ValueOf(fn)
+	if fnV.Kind() != reflect.Func {
+		panic("fn argument is not a function")
+	}
+
+	fnType := fnV.Type()
+
+	withContext := fnType.NumIn() > 0 && fnType.In(0) == contextType
+
+	offset := 0
+	if withContext {
+		offset = 1
+	}
+
+	if fnType.NumIn() <= offset {
+		panic("fn should have a parameter for values")
+	}
+
+	if fnType.IsVariadic() && fnType.NumIn()-1 == offset {
+		panic("fn values parameter should not be variadic")
+	}
+
+	numOut := fnType.NumOut()
+	withError := numOut > 0 && fnType.Out(numOut-1) == errorType
+	if withError {
+		numOut--
+	}
+
+	if numOut > 1 {
+		panic("fn should return at most one value and an error")
+	}
+
+	o := &typedOption{
+		fn:       fnV,
+		fnType:   fnType,
+		offset:   offset,
+		hasValue: numOut == 1,
+	}
+
+	return OptionFunc{
+		Name:      name,
+		FnContext: o.call,
+	}
+}
+
+// typedOption converts values and arguments to the parameter types of the function.
+type typedOption struct {
+	fn     reflect.Value
+	fnType reflect.Type
+	// offset is index of the values parameter.
+	offset   int
+	hasValue bool
+}
+
+func (o *typedOption) call(ctx context.Context, v []reflect.Value, args ...string) ([]reflect.Value, error) {
+	in := make([]reflect.Value, 0, o.fnType.NumIn())
+	if o.offset > 0 {
+		in = append(in, reflect.ValueOf(ctx))
+	}
+
+	value, err := o.value(v)
+	if err != nil {
+		return nil, err
+	}
+
+	in = append(in, value)
+
+	argsIn, err := o.args(args)
+	if err != nil {
+		return nil, err
+	}
+
+	in = append(in, argsIn...)
+
+	returns, err := splitError(o.fn.Call(in))
+	if err != nil {
+		return nil, err
+	}
+
+	if !o.hasValue {
+		return v, nil
+	}
+
+	return returns, nil
+}
+
+// value converts values to the values parameter.
+func (o *typedOption) value(v []reflect.Value) (reflect.Value, error) {
+	t := o.fnType.In(o.offset)
+
+	if t.Kind() != reflect.Slice {
+		if len(v) != 1 {
+			return reflect.Value{}, fmt.Errorf("want 1 value, got %d", len(v))
+		}
+
+		return toType(v[0], t)
+	}
+
+	// single value which is already a slice or has elements to convert
+	if len(v) == 1 {
+		if value, err := toType(v[0], t); err == nil {
+			return value, nil
+		}
+
+		if values, single, err := collection(v, ""); err == nil && single {
+			v = values
+		}
+	}
+
+	slice := reflect.MakeSlice(t, len(v), len(v))
+	for i, value := range v {
+		converted, err := toType(value, t.Elem())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("value %d: %w", i, err)
+		}
+
+		slice.Index(i).Set(converted)
+	}
+
+	return slice, nil
+}
+
+// args parses option arguments to the parameter types.
+func (o *typedOption) args(args []string) ([]reflect.Value, error) {
+	numIn := o.fnType.NumIn() - o.offset - 1
+	if o.fnType.IsVariadic() {
+		if len(args) < numIn-1 {
+			return nil, fmt.Errorf("%w: want at least %d arguments, got %d", ErrArgCountMismatch, numIn-1, len(args))
+		}
+	} else if len(args) != numIn {
+		return nil, fmt.Errorf("%w: want %d arguments, got %d", ErrArgCountMismatch, numIn, len(args))
+	}
+
+	in := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		t, _ := parameterType(o.fnType, o.offset+1+i)
+
+		v, err := parseText(arg, t)
+		if err != nil {
+			return nil, fmt.Errorf("argument %d: %w", i, err)
+		}
+
+		in[i] = v
+	}
+
+	return in, nil
+}
+
+// toType converts value to t, strings are parsed and nil values are zero.
+func toType(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+	v = elem(v)
+	if !v.IsValid() {
+		return reflect.Zero(t), nil
+	}
+
+	if value, ok, err := parseRaw(v, t); ok {
+		return value, err
+	}
+
+	if v.Type().AssignableTo(t) {
+		return v, nil
+	}
+
+	return convertValue(v, t)
+}
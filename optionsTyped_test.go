@@ -0,0 +1,197 @@
+package call
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewOptionFunc(t *testing.T) {
+	type ctxKey struct{}
+
+	tests := []struct {
+		name       string
+		fn         any
+		v          []reflect.Value
+		args       []string
+		want       []any
+		wantErr    error
+		wantErrStr string
+	}{
+		{
+			name: "slice values",
+			fn: func(v []int, n int) ([]int, error) {
+				ret := make([]int, len(v))
+				for i := range v {
+					ret[i] = v[i] + n
+				}
+
+				return ret, nil
+			},
+			v:    []reflect.Value{reflect.ValueOf(1), reflect.ValueOf(int8(2)), reflect.ValueOf("3")},
+			args: []string{"10"},
+			want: []any{[]int{11, 12, 13}},
+		},
+		{
+			name: "single slice value",
+			fn:   func(v []int) int { return len(v) },
+			v:    []reflect.Value{reflect.ValueOf([]int{1, 2})},
+			want: []any{2},
+		},
+		{
+			name: "single value and variadic arguments",
+			fn: func(s string, parts ...string) string {
+				return s + strings.Join(parts, "")
+			},
+			v:    []reflect.Value{reflect.ValueOf("a")},
+			args: []string{"b", "c"},
+			want: []any{"abc"},
+		},
+		{
+			name: "context",
+			fn: func(ctx context.Context, v int) int {
+				return v * ctx.Value(ctxKey{}).(int)
+			},
+			v:    []reflect.Value{reflect.ValueOf(3)},
+			want: []any{6},
+		},
+		{
+			name: "only error keeps values",
+			fn: func(v int, min int) error {
+				if v < min {
+					return fmt.Errorf("%d is less than %d", v, min)
+				}
+
+				return nil
+			},
+			v:    []reflect.Value{reflect.ValueOf(5)},
+			args: []string{"1"},
+			want: []any{5},
+		},
+		{
+			name: "returned error",
+			fn: func(v int, min int) error {
+				return fmt.Errorf("%d is less than %d", v, min)
+			},
+			v:          []reflect.Value{reflect.ValueOf(5)},
+			args:       []string{"10"},
+			wantErrStr: "5 is less than 10",
+		},
+		{
+			name:       "argument count",
+			fn:         func(v int, n int) int { return v + n },
+			v:          []reflect.Value{reflect.ValueOf(5)},
+			wantErr:    ErrArgCountMismatch,
+			wantErrStr: "argument count mismatch: want 1 arguments, got 0",
+		},
+		{
+			name:       "argument type",
+			fn:         func(v int, n int) int { return v + n },
+			v:          []reflect.Value{reflect.ValueOf(5)},
+			args:       []string{"x"},
+			wantErrStr: `argument 0: strconv.ParseInt: parsing "x": invalid syntax`,
+		},
+		{
+			name:       "value count",
+			fn:         func(v int) int { return v },
+			v:          []reflect.Value{reflect.ValueOf(5), reflect.ValueOf(6)},
+			wantErrStr: "want 1 value, got 2",
+		},
+		{
+			name:       "value type",
+			fn:         func(v []int) int { return len(v) },
+			v:          []reflect.Value{reflect.ValueOf(5), reflect.ValueOf(true)},
+			wantErrStr: "value 1: cannot convert bool to int",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewOptionFunc("test", tt.fn)
+
+			ctx := context.WithValue(context.Background(), ctxKey{}, 2)
+
+			got, err := o.FnContext(ctx, tt.v, tt.args...)
+			if err != nil {
+				if err.Error() != tt.wantErrStr {
+					t.Errorf("option error = %v, wantErrStr %v", err, tt.wantErrStr)
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("option error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if tt.wantErrStr != "" {
+				t.Fatalf("option error = nil, wantErrStr %v", tt.wantErrStr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("option = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !reflect.DeepEqual(got[i].Interface(), tt.want[i]) {
+					t.Errorf("option = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewOptionFunc_panic(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   any
+	}{
+		{name: "not a function", fn: 1},
+		{name: "no values parameter", fn: func(context.Context) int { return 0 }},
+		{name: "variadic values", fn: func(...int) int { return 0 }},
+		{name: "multiple returns", fn: func(int) (int, int) { return 0, 0 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewOptionFunc() did not panic")
+				}
+			}()
+
+			NewOptionFunc("test", tt.fn)
+		})
+	}
+}
+
+func TestNewOptionFunc_call(t *testing.T) {
+	reg := NewReg(NewOptionFunc("mul", func(v []int, n int) []int {
+		for i := range v {
+			v[i] *= n
+		}
+
+		return v
+	})).
+		AddArgument("list", []any{1, 2, 3}).
+		AddFunction("sum", func(v ...int) int {
+			total := 0
+			for _, n := range v {
+				total += n
+			}
+
+			return total
+		})
+
+	got, err := reg.CallWithArgs("sum", "list:mul=2;...")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got[0] != 12 {
+		t.Errorf("sum = %v, want 12", got[0])
+	}
+
+	_, err = reg.CallWithArgs("sum", "list:mul")
+
+	var callErr *CallError
+	if !errors.As(err, &callErr) || callErr.Option != "mul" || !errors.Is(err, ErrArgCountMismatch) {
+		t.Errorf("error = %v, want mul option error", err)
+	}
+}
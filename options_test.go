@@ -1,6 +1,8 @@
 package call
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"testing"
@@ -37,7 +39,7 @@ func TestOptions_VisitOptions(t *testing.T) {
 			},
 			want:       nil,
 			wantErr:    true,
-			wantErrStr: `option option2 not found`,
+			wantErrStr: `option2; option not found`,
 		},
 		{
 			name: "option error",
@@ -97,3 +99,107 @@ func TestOptions_VisitOptions(t *testing.T) {
 		})
 	}
 }
+
+func TestOptions_VisitOptionsContext(t *testing.T) {
+	type ctxKey struct{}
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "ctx"))
+	defer cancel()
+
+	o := newOptions()
+	o.AddOptionContext("value", func(ctx context.Context, _ []reflect.Value, _ ...string) ([]reflect.Value, error) {
+		return []reflect.Value{reflect.ValueOf(ctx.Value(ctxKey{}))}, nil
+	})
+	o.AddOptionContext("cancel", func(_ context.Context, v []reflect.Value, _ ...string) ([]reflect.Value, error) {
+		cancel()
+
+		return v, nil
+	})
+
+	got, err := o.VisitOptionsContext(ctx, "test:value", "test")
+	if err != nil {
+		t.Fatalf("Options.VisitOptionsContext() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Interface() != "ctx" {
+		t.Errorf("Options.VisitOptionsContext() = %v, want %v", got, "ctx")
+	}
+
+	got, err = o.VisitOptions("test:value", "test")
+	if err != nil {
+		t.Fatalf("Options.VisitOptions() error = %v", err)
+	}
+	if len(got) != 1 || got[0].IsValid() {
+		t.Errorf("Options.VisitOptions() = %v, want invalid value", got)
+	}
+
+	if _, err := o.VisitOptionsContext(ctx, "test:cancel;value", "test"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Options.VisitOptionsContext() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestReg_OptionWithoutContext(t *testing.T) {
+	type ctxKey struct{}
+
+	// plainOption hides context methods of Options
+	type plainOption struct{ Option }
+
+	r := NewReg()
+	r.Option = plainOption{Option: r.Option}
+
+	r.AddOptionContext("value", func(ctx context.Context, _ []reflect.Value, _ ...string) ([]reflect.Value, error) {
+		return []reflect.Value{reflect.ValueOf(ctx.Value(ctxKey{}) == nil)}, nil
+	})
+	r.AddArgument("test", "test")
+	r.AddFunction("test", func(v bool) bool { return v }, "test:value")
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "ctx")
+
+	got, err := r.CallContext(ctx, "test")
+	if err != nil {
+		t.Fatalf("Reg.CallContext() error = %v", err)
+	}
+	if got[0] != true {
+		t.Errorf("Reg.CallContext() = %v, want background context in option", got[0])
+	}
+}
+
+// recordOption is a custom Option with its own delimeter.
+type recordOption struct {
+	Option
+	visited []string
+}
+
+func (o *recordOption) GetDelimeter() string {
+	return "#"
+}
+
+func (o *recordOption) VisitOptions(arg string, v any) ([]reflect.Value, error) {
+	o.visited = append(o.visited, arg)
+
+	return []reflect.Value{reflect.ValueOf(v.(int) * 2)}, nil
+}
+
+func TestReg_CustomOption(t *testing.T) {
+	option := &recordOption{}
+
+	r := NewReg()
+	option.Option = r.Option
+	r.Option = option
+
+	r.AddArgument("a#ignored", 2).
+		AddFunction("f", func(v int) int { return v }, "a#double")
+
+	for i := 0; i < 2; i++ {
+		got, err := r.Call("f")
+		if err != nil {
+			t.Fatalf("Reg.Call() error = %v", err)
+		}
+		if got[0] != 4 {
+			t.Errorf("Reg.Call() = %v, want 4", got[0])
+		}
+	}
+
+	if want := []string{"a#double", "a#double"}; !reflect.DeepEqual(option.visited, want) {
+		t.Errorf("VisitOptions() args = %v, want %v", option.visited, want)
+	}
+}
@@ -0,0 +1,301 @@
+package call
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// argumentName trims options of the name.
+//
+// Names can have characters of the expressions, they are referenced with quotes or escapes
+// like `"a|b"` or `a\?`.
+func argumentName(name, delimeter string) string {
+	return strings.SplitN(name, delimeter, 2)[0]
+}
+
+// ArgExpr is parsed argument expression.
+//
+//	name:option1=value1,value2;option2
+//	primary|replica?:option1
+type ArgExpr struct {
+	// Name is the argument name.
+	Name string
+	// Fallbacks are tried in order when argument is not found.
+	Fallbacks []string
+	// Optional argument is nil when it is not found.
+	Optional bool
+	// Literal expression starts with `=` and has JSON value in Value, it has no name and options.
+	Literal bool
+	Value   string
+	Options []OptionExpr
+}
+
+// OptionExpr is an option of the argument expression.
+type OptionExpr struct {
+	Name string
+	// Args is nil when option has no `=`.
+	Args []string
+	// Column is the position of the option in expression, starts from 1.
+	Column int
+}
+
+// SyntaxError is returned when argument expression cannot be parsed.
+type SyntaxError struct {
+	Expr string
+	// Column is the position of the error in expression, starts from 1.
+	Column int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at column %d near %q: %s", e.Column, e.snippet(), e.Msg)
+}
+
+// snippet returns part of the expression starting from the error column.
+func (e *SyntaxError) snippet() string {
+	const size = 10
+
+	runes := []rune(e.Expr)
+
+	start := e.Column - 1
+	if start > len(runes) {
+		start = len(runes)
+	}
+
+	end := start + size
+	if end > len(runes) {
+		end = len(runes)
+	}
+
+	return string(runes[start:end])
+}
+
+// ParseArgExpr parses argument expression.
+//
+// Argument name is separated from options with `:`, options are separated with `;`
+// and option arguments are given after `=` separated with `,`.
+//
+// Argument names can have fallbacks separated with `|` and `?` at the end of names
+// makes argument optional.
+//
+// Values can be quoted with double quotes to use separators in them and backslash escapes
+// the next character in or out of quotes. Empty option arguments are kept.
+//
+//	m:index="host:port","a,b";...
+//	m:index=a\,b,,c
+//	primaryDB|replicaDB
+//	user?
+//
+// Expression starting with `=` is a JSON literal which is converted to the parameter type.
+//
+//	=42
+//	="hello"
+//	=[1,2,3]
+func ParseArgExpr(expr string) (*ArgExpr, error) {
+	p := &exprParser{expr: expr}
+
+	if strings.HasPrefix(expr, "=") {
+		return p.literal()
+	}
+
+	argExpr := &ArgExpr{}
+
+	for {
+		start := p.pos
+
+		name, err := p.word(":|?")
+		if err != nil {
+			return nil, err
+		}
+
+		if name == "" {
+			return nil, p.errorf(start, "empty argument name")
+		}
+
+		if argExpr.Name == "" {
+			argExpr.Name = name
+		} else {
+			argExpr.Fallbacks = append(argExpr.Fallbacks, name)
+		}
+
+		if !p.done() && p.expr[p.pos] == '?' {
+			argExpr.Optional = true
+			p.pos++
+
+			if !p.done() && p.expr[p.pos] != ':' {
+				return nil, p.errorf(p.pos, "optional mark should be at the end of names")
+			}
+		}
+
+		if p.done() || p.expr[p.pos] != '|' {
+			break
+		}
+
+		// skip '|'
+		p.pos++
+	}
+
+	if p.done() {
+		return argExpr, nil
+	}
+
+	// skip ':'
+	p.pos++
+
+	for {
+		start := p.pos
+
+		optName, err := p.word(";=")
+		if err != nil {
+			return nil, err
+		}
+
+		if optName == "" {
+			return nil, p.errorf(start, "empty option name")
+		}
+
+		option := OptionExpr{
+			Name:   optName,
+			Column: p.column(start),
+		}
+
+		if !p.done() && p.expr[p.pos] == '=' {
+			// option arguments
+			option.Args = []string{}
+
+			for {
+				p.pos++
+
+				v, err := p.word(";,")
+				if err != nil {
+					return nil, err
+				}
+
+				option.Args = append(option.Args, v)
+
+				if p.done() || p.expr[p.pos] != ',' {
+					break
+				}
+			}
+		}
+
+		argExpr.Options = append(argExpr.Options, option)
+
+		if p.done() {
+			return argExpr, nil
+		}
+
+		// skip ';'
+		p.pos++
+	}
+}
+
+// literal parses JSON literal after `=`.
+func (p *exprParser) literal() (*ArgExpr, error) {
+	value := p.expr[1:]
+	if strings.TrimSpace(value) == "" {
+		return nil, p.errorf(1, "empty literal")
+	}
+
+	var v any
+	if err := json.Unmarshal([]byte(value), &v); err != nil {
+		pos := 1
+		if errSyntax, ok := err.(*json.SyntaxError); ok && errSyntax.Offset > 0 {
+			pos += int(errSyntax.Offset) - 1
+		}
+
+		return nil, p.errorf(pos, "invalid literal: %v", err)
+	}
+
+	return &ArgExpr{Literal: true, Value: value}, nil
+}
+
+// exprParser keeps position in the expression.
+type exprParser struct {
+	expr string
+	pos  int
+}
+
+func (p *exprParser) done() bool {
+	return p.pos >= len(p.expr)
+}
+
+// column returns 1-based character position of the byte offset.
+func (p *exprParser) column(pos int) int {
+	return utf8.RuneCountInString(p.expr[:pos]) + 1
+}
+
+func (p *exprParser) errorf(pos int, format string, args ...any) error {
+	return &SyntaxError{
+		Expr:   p.expr,
+		Column: p.column(pos),
+		Msg:    fmt.Sprintf(format, args...),
+	}
+}
+
+// word reads value until one of the stop characters which is not quoted or escaped.
+func (p *exprParser) word(stops string) (string, error) {
+	var b strings.Builder
+
+	for !p.done() {
+		c := p.expr[p.pos]
+
+		switch {
+		case c == '\\':
+			if err := p.escape(&b); err != nil {
+				return "", err
+			}
+		case c == '"':
+			if err := p.quoted(&b); err != nil {
+				return "", err
+			}
+		case strings.IndexByte(stops, c) >= 0:
+			return b.String(), nil
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+
+	return b.String(), nil
+}
+
+// escape writes the character after backslash.
+func (p *exprParser) escape(b *strings.Builder) error {
+	if p.pos+1 >= len(p.expr) {
+		return p.errorf(p.pos, "escape at end of expression")
+	}
+
+	r, size := utf8.DecodeRuneInString(p.expr[p.pos+1:])
+	b.WriteRune(r)
+	p.pos += 1 + size
+
+	return nil
+}
+
+// quoted writes the quoted string without quotes.
+func (p *exprParser) quoted(b *strings.Builder) error {
+	start := p.pos
+	// skip '"'
+	p.pos++
+
+	for !p.done() {
+		switch c := p.expr[p.pos]; c {
+		case '\\':
+			if err := p.escape(b); err != nil {
+				return err
+			}
+		case '"':
+			p.pos++
+
+			return nil
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+
+	return p.errorf(start, "unterminated quoted string")
+}
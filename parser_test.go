@@ -0,0 +1,236 @@
+package call
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseArgExpr(t *testing.T) {
+	tests := []struct {
+		name       string
+		expr       string
+		want       *ArgExpr
+		wantErrStr string
+		wantColumn int
+	}{
+		{
+			name: "only name",
+			expr: "arg",
+			want: &ArgExpr{Name: "arg"},
+		},
+		{
+			name: "options",
+			expr: "arg:index=0,1;...",
+			want: &ArgExpr{Name: "arg", Options: []OptionExpr{
+				{Name: "index", Args: []string{"0", "1"}, Column: 5},
+				{Name: "...", Column: 15},
+			}},
+		},
+		{
+			name: "quoted values",
+			expr: `arg:index="a,b","host:port",x"y;z"`,
+			want: &ArgExpr{Name: "arg", Options: []OptionExpr{
+				{Name: "index", Args: []string{"a,b", "host:port", "xy;z"}, Column: 5},
+			}},
+		},
+		{
+			name: "escapes",
+			expr: `a\:b:index=a\,b,"q\"uote",\\`,
+			want: &ArgExpr{Name: "a:b", Options: []OptionExpr{
+				{Name: "index", Args: []string{"a,b", `q"uote`, `\`}, Column: 6},
+			}},
+		},
+		{
+			name: "empty values",
+			expr: `arg:index=,"",;x=`,
+			want: &ArgExpr{Name: "arg", Options: []OptionExpr{
+				{Name: "index", Args: []string{"", "", ""}, Column: 5},
+				{Name: "x", Args: []string{""}, Column: 16},
+			}},
+		},
+		{
+			name: "equal and colon in value",
+			expr: "arg:index=a=b:c",
+			want: &ArgExpr{Name: "arg", Options: []OptionExpr{
+				{Name: "index", Args: []string{"a=b:c"}, Column: 5},
+			}},
+		},
+		{
+			name: "fallbacks and optional",
+			expr: "primary|replica?:index=0",
+			want: &ArgExpr{Name: "primary", Fallbacks: []string{"replica"}, Optional: true, Options: []OptionExpr{
+				{Name: "index", Args: []string{"0"}, Column: 18},
+			}},
+		},
+		{
+			name: "escaped fallback",
+			expr: `a\|b\?`,
+			want: &ArgExpr{Name: "a|b?"},
+		},
+		{
+			name:       "empty fallback",
+			expr:       "a||b",
+			wantErrStr: `syntax error at column 3 near "|b": empty argument name`,
+			wantColumn: 3,
+		},
+		{
+			name:       "optional in middle",
+			expr:       "a?|b",
+			wantErrStr: `syntax error at column 3 near "|b": optional mark should be at the end of names`,
+			wantColumn: 3,
+		},
+		{
+			name: "literal",
+			expr: `={"a": [1, "b:c"]}`,
+			want: &ArgExpr{Literal: true, Value: `{"a": [1, "b:c"]}`},
+		},
+		{
+			name:       "empty literal",
+			expr:       "=",
+			wantErrStr: `syntax error at column 2 near "": empty literal`,
+			wantColumn: 2,
+		},
+		{
+			name:       "invalid literal",
+			expr:       "=[1,2",
+			wantErrStr: `syntax error at column 5 near "2": invalid literal: unexpected end of JSON input`,
+			wantColumn: 5,
+		},
+		{
+			name:       "invalid literal value",
+			expr:       "=hello",
+			wantErrStr: `syntax error at column 2 near "hello": invalid literal: invalid character 'h' looking for beginning of value`,
+			wantColumn: 2,
+		},
+		{
+			name:       "empty name",
+			expr:       ":index=0",
+			wantErrStr: `syntax error at column 1 near ":index=0": empty argument name`,
+			wantColumn: 1,
+		},
+		{
+			name:       "empty option",
+			expr:       "arg:index=0;;...",
+			wantErrStr: `syntax error at column 13 near ";...": empty option name`,
+			wantColumn: 13,
+		},
+		{
+			name:       "trailing colon",
+			expr:       "arg:",
+			wantErrStr: `syntax error at column 5 near "": empty option name`,
+			wantColumn: 5,
+		},
+		{
+			name:       "unterminated quote",
+			expr:       `ärg:index="abc,def,ghi`,
+			wantErrStr: `syntax error at column 11 near "\"abc,def,g": unterminated quoted string`,
+			wantColumn: 11,
+		},
+		{
+			name:       "escape at end",
+			expr:       `arg:index=a\`,
+			wantErrStr: `syntax error at column 12 near "\\": escape at end of expression`,
+			wantColumn: 12,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseArgExpr(tt.expr)
+			if (err != nil) != (tt.wantErrStr != "") {
+				t.Fatalf("ParseArgExpr() error = %v, wantErrStr %v", err, tt.wantErrStr)
+			}
+			if err != nil {
+				if err.Error() != tt.wantErrStr {
+					t.Errorf("ParseArgExpr() error = %v, wantErrStr %v", err, tt.wantErrStr)
+				}
+
+				var syntaxErr *SyntaxError
+				if !errors.As(err, &syntaxErr) || syntaxErr.Column != tt.wantColumn {
+					t.Errorf("ParseArgExpr() error = %#v, wantColumn %v", err, tt.wantColumn)
+				}
+
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseArgExpr() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReg_CallQuoted(t *testing.T) {
+	r := NewReg().
+		AddArgument("m", map[string]string{"a,b": "comma", "host:port": "colon"}).
+		AddFunction("join", func(v ...string) string {
+			ret := ""
+			for _, s := range v {
+				ret += s
+			}
+
+			return ret
+		})
+
+	got, err := r.CallWithArgs("join", `m:index="a,b","host:port"`)
+	if err != nil {
+		t.Fatalf("Reg.CallWithArgs() error = %v", err)
+	}
+	if want := []any{"commacolon"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Reg.CallWithArgs() = %v, want %v", got, want)
+	}
+
+	_, err = r.CallWithArgs("join", `m:index="a,b`)
+
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) || syntaxErr.Column != 9 {
+		t.Errorf("Reg.CallWithArgs() error = %v, want SyntaxError", err)
+	}
+
+	wantErrStr := `resolve function join argument m:index="a,b: syntax error at column 9 near "\"a,b": unterminated quoted string`
+	if err == nil || err.Error() != wantErrStr {
+		t.Errorf("Reg.CallWithArgs() error = %v, wantErrStr %v", err, wantErrStr)
+	}
+}
+
+func TestReg_AddArgumentReservedName(t *testing.T) {
+	r := NewReg().
+		AddArgument("a|b", 1).
+		AddArgument("a?", 2).
+		AddArgument(`a"b`, 3).
+		AddArgument(`a\b`, 4).
+		AddArgument("=a", 5).
+		AddFunction("sum", func(v ...int) int {
+			sum := 0
+			for _, n := range v {
+				sum += n
+			}
+
+			return sum
+		})
+
+	got, err := r.CallWithArgs("sum", `"a|b"`, `a\?`, `a\"b`, `"a\\b"`, `\=a`)
+	if err != nil {
+		t.Fatalf("Reg.CallWithArgs() error = %v", err)
+	}
+	if want := []any{15}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Reg.CallWithArgs() = %v, want %v", got, want)
+	}
+
+	// options are trimmed
+	if _, ok := NewReg().AddArgument("a:b|c", 1).GetArgument("a"); !ok {
+		t.Errorf("Reg.AddArgument() should trim options")
+	}
+}
+
+func TestOptions_ParseOption(t *testing.T) {
+	o := &Options{}
+
+	got := o.ParseOption(`m:index="a;b",c;...`)
+	if want := []string{"index=a;b,c", "..."}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Options.ParseOption() = %v, want %v", got, want)
+	}
+
+	if got := o.ParseOption(`m:index="a`); got != nil {
+		t.Errorf("Options.ParseOption() = %v, want nil", got)
+	}
+}
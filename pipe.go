@@ -0,0 +1,111 @@
+package call
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// pipeValue is the argument placeholder of the values from the previous step.
+const pipeValue = "=null"
+
+// Pipe calls functions in order and gives non-error returns of each step as leading
+// arguments of the next step, it returns non-error returns of the last step.
+//
+// Step is a function name and `arg` option gives the rest of the arguments, registered
+// arguments of the function are used without `arg` option.
+//
+//	reg.Pipe("load", "transform:arg=mode", "save")
+//
+// Pipe stops at the first error, errors are *PipeError with the step.
+func (r *Reg) Pipe(steps ...string) ([]any, error) {
+	return r.pipe(context.Background(), false, steps)
+}
+
+// PipeContext is like Pipe but functions are called like CallWithArgsContext.
+func (r *Reg) PipeContext(ctx context.Context, steps ...string) ([]any, error) {
+	return r.pipe(ctx, true, steps)
+}
+
+func (r *Reg) pipe(ctx context.Context, withContext bool, steps []string) ([]any, error) {
+	var values []reflect.Value
+
+	for i, step := range steps {
+		name, args, err := r.pipeStep(step)
+		if err != nil {
+			return nil, &PipeError{Step: i, Function: step, Err: err}
+		}
+
+		values, err = r.pipeCall(ctx, withContext, name, args, values)
+		if err != nil {
+			return nil, &PipeError{Step: i, Function: name, Err: err}
+		}
+	}
+
+	return interfaces(values), nil
+}
+
+// pipeStep returns function name and arguments of the step.
+func (r *Reg) pipeStep(step string) (string, []string, error) {
+	expr, err := ParseArgExpr(step)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if expr.Literal || expr.Optional || len(expr.Fallbacks) > 0 {
+		return "", nil, fmt.Errorf("step should be a function name")
+	}
+
+	var args []string
+
+	for _, option := range expr.Options {
+		if option.Name != "arg" {
+			return "", nil, fmt.Errorf("unknown step option %s", option.Name)
+		}
+
+		args = append(args, option.Args...)
+	}
+
+	if args == nil {
+		f, _ := r.GetFunction(expr.Name)
+		args = f.Args
+	}
+
+	return expr.Name, args, nil
+}
+
+// pipeCall calls the function with leading values and returns non-error returns.
+func (r *Reg) pipeCall(ctx context.Context, withContext bool, name string, args []string, leading []reflect.Value) ([]reflect.Value, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, newCallError(StageResolve, name, "", err)
+	}
+
+	f, ok := r.GetFunction(name)
+	if !ok {
+		return nil, newCallError(StageResolve, name, "", ErrFunctionNotFound)
+	}
+
+	state := &callState{ctx: ctx, withContext: withContext}
+
+	// placeholders keep positions of the leading values, also for autowire
+	stepArgs := make([]string, 0, len(leading)+len(args))
+	for range leading {
+		stepArgs = append(stepArgs, pipeValue)
+	}
+
+	c, err := r.compile(state, name, f, append(stepArgs, args...))
+	if err != nil {
+		return nil, err
+	}
+
+	for i, v := range leading {
+		c.args[i] = argPlan{name: fmt.Sprintf("pipe value %d", i), literal: v}
+	}
+
+	returns, err := r.execute(state, name, c)
+	if err != nil {
+		return nil, err
+	}
+
+	return splitError(returns)
+}
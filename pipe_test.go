@@ -0,0 +1,130 @@
+package call
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReg_Pipe(t *testing.T) {
+	errSave := errors.New("save failed")
+
+	reg := NewReg().
+		AddArgument("path", "data.txt").
+		AddArgument("mode", "upper").
+		AddArgument("suffix", "!").
+		AddFunction("load", func(path string) (string, int, error) {
+			return "content of " + path, len(path), nil
+		}, "path").
+		AddFunction("transform", func(s string, n int, mode string) (string, error) {
+			if mode != "upper" {
+				return "", fmt.Errorf("unknown mode %s", mode)
+			}
+
+			return fmt.Sprintf("%s %d", strings.ToUpper(s), n), nil
+		}).
+		AddFunction("suffix", func(s, suffix string) string { return s + suffix }, "suffix").
+		AddFunction("save", func(string) error { return errSave }).
+		AddArgument("count", 3).
+		AddFunctionAuto("auto", func(s string, ctx context.Context, count int) string {
+			return fmt.Sprint(s, " ", count, " ", ctx != nil)
+		}).
+		AddFunction("panic", func(string) { panic("step") })
+
+	tests := []struct {
+		name       string
+		steps      []string
+		want       []any
+		wantErr    error
+		wantStep   int
+		wantErrStr string
+	}{
+		{
+			name:  "steps with arguments",
+			steps: []string{"load", "transform:arg=mode", "suffix"},
+			want:  []any{"CONTENT OF DATA.TXT 8!"},
+		},
+		{
+			name:  "step arguments replace registered arguments",
+			steps: []string{"load", `transform:arg==\"upper\"`, `suffix:arg==\"?\"`},
+			want:  []any{"CONTENT OF DATA.TXT 8?"},
+		},
+		{
+			name:  "autowire",
+			steps: []string{"load:arg=mode", "transform:arg=mode", "auto"},
+			want:  []any{"CONTENT OF UPPER 5 3 true"},
+		},
+		{
+			name:       "returned error",
+			steps:      []string{"load", "transform:arg=mode", "save", "suffix"},
+			wantErr:    errSave,
+			wantStep:   2,
+			wantErrStr: "pipe step 2 function save: save failed",
+		},
+		{
+			name:       "step function error",
+			steps:      []string{"load", "transform:arg=path"},
+			wantStep:   1,
+			wantErrStr: "pipe step 1 function transform: unknown mode data.txt",
+		},
+		{
+			name:       "argument count",
+			steps:      []string{"load", "suffix"},
+			wantErr:    ErrArgCountMismatch,
+			wantStep:   1,
+			wantErrStr: "pipe step 1 function suffix: typecheck function suffix: argument count mismatch: want 2, got 3",
+		},
+		{
+			name:       "panic",
+			steps:      []string{"load:arg=mode", "transform:arg=mode", "panic"},
+			wantStep:   2,
+			wantErrStr: "pipe step 2 function panic: invoke function panic: panic: step",
+		},
+		{
+			name:       "function not found",
+			steps:      []string{"load", "missing"},
+			wantErr:    ErrFunctionNotFound,
+			wantStep:   1,
+			wantErrStr: "pipe step 1 function missing: resolve function missing: function not found",
+		},
+		{
+			name:       "unknown step option",
+			steps:      []string{"load:index=0"},
+			wantErrStr: "pipe step 0 function load:index=0: unknown step option index",
+		},
+		{
+			name:       "not a function name",
+			steps:      []string{"load|save"},
+			wantErrStr: "pipe step 0 function load|save: step should be a function name",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := reg.PipeContext(context.Background(), tt.steps...)
+			if err != nil {
+				if err.Error() != tt.wantErrStr {
+					t.Errorf("Reg.Pipe() error = %v, wantErrStr %v", err, tt.wantErrStr)
+				}
+
+				var pipeErr *PipeError
+				if !errors.As(err, &pipeErr) || pipeErr.Step != tt.wantStep {
+					t.Errorf("Reg.Pipe() error = %#v, want step %d", err, tt.wantStep)
+				}
+
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("Reg.Pipe() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if tt.wantErrStr != "" {
+				t.Fatalf("Reg.Pipe() error = nil, wantErrStr %v", tt.wantErrStr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Reg.Pipe() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
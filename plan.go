@@ -0,0 +1,177 @@
+package call
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+)
+
+// planCacheSize limits count of the plans cached by calls with arguments.
+const planCacheSize = 256
+
+// planKey is the key of the plans cached by calls.
+type planKey struct {
+	withContext bool
+	name        string
+	// args joined with zero byte.
+	args string
+}
+
+// Plan is a prepared call of a function.
+//
+// Arguments are parsed and option functions are found once, plan is compiled again
+// when arguments, functions or options of the registry change.
+// Plan is safe to use concurrently.
+type Plan struct {
+	reg  *Reg
+	name string
+	args []string
+	// registered uses function's registered arguments.
+	registered bool
+	compiled   atomic.Pointer[compiledPlan]
+}
+
+// Prepare returns plan of the function with arguments.
+//
+// Without args, registered arguments of the function are used.
+// Unknown options of the arguments are reported by Prepare.
+func (r *Reg) Prepare(name string, args ...string) (*Plan, error) {
+	p := &Plan{
+		reg:        r,
+		name:       name,
+		args:       args,
+		registered: len(args) == 0,
+	}
+
+	c, err := p.compile(&callState{ctx: context.Background()})
+	if err != nil {
+		return nil, err
+	}
+
+	// calls report unknown options when they are reached, plan reports them early
+	for _, arg := range c.args {
+		for _, option := range arg.options {
+			if option.fn == nil {
+				return nil, newCallError(StageOption, name, arg.name, &OptionError{Option: option.name, Err: ErrOptionNotFound})
+			}
+		}
+	}
+
+	return p, nil
+}
+
+// Call calls function of the plan.
+func (p *Plan) Call() ([]any, error) {
+	return p.call(context.Background(), false)
+}
+
+// CallContext calls function of the plan with context like Reg.CallWithArgsContext.
+func (p *Plan) CallContext(ctx context.Context) ([]any, error) {
+	return p.call(ctx, true)
+}
+
+func (p *Plan) call(ctx context.Context, withContext bool) ([]any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, newCallError(StageResolve, p.name, "", err)
+	}
+
+	state := &callState{ctx: ctx, withContext: withContext}
+
+	c, err := p.compile(state)
+	if err != nil {
+		return nil, err
+	}
+
+	returnV, err := p.reg.execute(state, p.name, c)
+	if err != nil {
+		return nil, err
+	}
+
+	return interfaces(returnV), nil
+}
+
+// compile returns compiled plan, it compiles again if registry is changed.
+func (p *Plan) compile(state *callState) (*compiledPlan, error) {
+	version, known := p.reg.planVersion()
+
+	if c := p.compiled.Load(); known && c != nil && c.version == version && c.withContext == state.withContext {
+		return c, nil
+	}
+
+	f, ok := p.reg.GetFunction(p.name)
+	if !ok {
+		return nil, newCallError(StageResolve, p.name, "", ErrFunctionNotFound)
+	}
+
+	args := p.args
+	if p.registered {
+		args = f.Args
+	}
+
+	c, err := p.reg.compile(state, p.name, f, args)
+	if err != nil {
+		return nil, err
+	}
+
+	c.version = version
+	p.compiled.Store(c)
+
+	return c, nil
+}
+
+// planVersion returns number which changes when registry, its parents or options change.
+//
+// Version is unknown if options don't report their changes, plans are compiled on every call then.
+func (r *Reg) planVersion() (uint64, bool) {
+	o, ok := r.Option.(versioned)
+	if !ok {
+		return 0, false
+	}
+
+	version := o.Version()
+	for reg := r; reg != nil; reg = reg.parent {
+		version += reg.version.Load()
+	}
+
+	return version, true
+}
+
+// cachedCompile returns compiled plan of the call, plans are cached by function name and arguments.
+//
+// Cache is bounded with planCacheSize, calls with new arguments compile every time after that.
+func (r *Reg) cachedCompile(state *callState, name string, args []string) (*compiledPlan, error) {
+	version, known := r.planVersion()
+
+	key := planKey{withContext: state.withContext, name: name, args: strings.Join(args, "\x00")}
+
+	cached, inCache := r.plans.Load(key)
+	if inCache && known {
+		if c := cached.(*compiledPlan); c.version == version {
+			return c, nil
+		}
+	}
+
+	f, ok := r.GetFunction(name)
+	if !ok {
+		return nil, newCallError(StageResolve, name, "", ErrFunctionNotFound)
+	}
+
+	c, err := r.compile(state, name, f, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if !known {
+		return c, nil
+	}
+
+	c.version = version
+
+	if inCache || r.planCount.Load() < planCacheSize {
+		if _, loaded := r.plans.Swap(key, c); !loaded {
+			r.planCount.Add(1)
+		}
+	}
+
+	return c, nil
+}
@@ -0,0 +1,212 @@
+package call
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestReg_Prepare(t *testing.T) {
+	r := NewReg().
+		AddArgument("a", []int{1, 2, 3}).
+		AddArgument("b", 10).
+		AddFunction("sum", func(x ...int) int {
+			sum := 0
+			for _, v := range x {
+				sum += v
+			}
+
+			return sum
+		}, "a:...")
+
+	plan, err := r.Prepare("sum")
+	if err != nil {
+		t.Fatalf("Reg.Prepare() error = %v", err)
+	}
+
+	planArgs, err := r.Prepare("sum", "a:index=0,2", "b")
+	if err != nil {
+		t.Fatalf("Reg.Prepare() error = %v", err)
+	}
+
+	check := func(p *Plan, want []any) {
+		t.Helper()
+
+		got, err := p.Call()
+		if err != nil {
+			t.Fatalf("Plan.Call() error = %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Plan.Call() = %v, want %v", got, want)
+		}
+	}
+
+	check(plan, []any{6})
+	check(planArgs, []any{14})
+
+	// argument change
+	r.AddArgument("a", []int{5, 5, 5})
+	check(plan, []any{15})
+	check(planArgs, []any{20})
+
+	// function change
+	r.AddFunction("sum", func(x ...int) int { return len(x) }, "a:...", "b")
+	check(plan, []any{4})
+	check(planArgs, []any{3})
+
+	// option change
+	r.AddOption("...", func(v []reflect.Value, _ ...string) ([]reflect.Value, error) {
+		return []reflect.Value{v[0].Index(0)}, nil
+	})
+	check(plan, []any{2})
+
+	// context
+	r.AddFunction("ctx", func(ctx context.Context, v int) int { return v }, "b")
+
+	planCtx, err := r.Prepare("ctx")
+	if err != nil {
+		t.Fatalf("Reg.Prepare() error = %v", err)
+	}
+
+	got, err := planCtx.CallContext(context.Background())
+	if err != nil || !reflect.DeepEqual(got, []any{10}) {
+		t.Errorf("Plan.CallContext() = %v, %v, want %v", got, err, []any{10})
+	}
+
+	// missing function
+	r.DeleteFunction("sum")
+	if _, err := plan.Call(); !errors.Is(err, ErrFunctionNotFound) {
+		t.Errorf("Plan.Call() error = %v, want %v", err, ErrFunctionNotFound)
+	}
+
+	if _, err := r.Prepare("sum"); !errors.Is(err, ErrFunctionNotFound) {
+		t.Errorf("Reg.Prepare() error = %v, want %v", err, ErrFunctionNotFound)
+	}
+
+	if _, err := r.Prepare("ctx", "b:xyz"); !errors.Is(err, ErrOptionNotFound) {
+		t.Errorf("Reg.Prepare() error = %v, want %v", err, ErrOptionNotFound)
+	}
+}
+
+func TestPlan_Scope(t *testing.T) {
+	base := NewReg().AddFunction("get", func(v string) string { return v }, "name")
+	scope := base.NewScope().AddArgument("name", "scope")
+
+	plan, err := scope.Prepare("get")
+	if err != nil {
+		t.Fatalf("Reg.Prepare() error = %v", err)
+	}
+
+	base.AddFunction("get", func(v string) string { return v + "-changed" }, "name")
+
+	got, err := plan.Call()
+	if err != nil || !reflect.DeepEqual(got, []any{"scope-changed"}) {
+		t.Errorf("Plan.Call() = %v, %v, want %v", got, err, []any{"scope-changed"})
+	}
+}
+
+func benchmarkReg() *Reg {
+	return NewReg().
+		AddArgument("list", []int{1, 2, 3, 4}).
+		AddArgument("m", map[string]int{"a": 1, "b": 2}).
+		AddFunction("sum", func(x ...int) int {
+			sum := 0
+			for _, v := range x {
+				sum += v
+			}
+
+			return sum
+		})
+}
+
+var benchmarkArgs = []string{"list:index=0,1,2,3", "m:index=a", "m:index=b", "list:..."}
+
+func BenchmarkReg_CallWithArgs(b *testing.B) {
+	r := benchmarkReg()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := r.CallWithArgs("sum", benchmarkArgs...); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPlan_Call(b *testing.B) {
+	plan, err := benchmarkReg().Prepare("sum", benchmarkArgs...)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := plan.Call(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestPlan_OptionWithoutVersion(t *testing.T) {
+	// plainOption hides Version of Options
+	type plainOption struct{ Option }
+
+	r := NewReg()
+	r.Option = plainOption{Option: r.Option}
+
+	r.AddOption("value", func(_ []reflect.Value, _ ...string) ([]reflect.Value, error) {
+		return []reflect.Value{reflect.ValueOf(1)}, nil
+	})
+	r.AddArgument("a", 0)
+	r.AddFunction("test", func(v int) int { return v }, "a:value")
+
+	p, err := r.Prepare("test")
+	if err != nil {
+		t.Fatalf("Reg.Prepare() error = %v", err)
+	}
+
+	if got, err := p.Call(); err != nil || got[0] != 1 {
+		t.Fatalf("Plan.Call() = %v, %v, want 1", got, err)
+	}
+
+	// option changes are not reported, plan must not reuse the old option
+	r.AddOption("value", func(_ []reflect.Value, _ ...string) ([]reflect.Value, error) {
+		return []reflect.Value{reflect.ValueOf(2)}, nil
+	})
+
+	if got, err := p.Call(); err != nil || got[0] != 2 {
+		t.Errorf("Plan.Call() = %v, %v, want 2", got, err)
+	}
+}
+
+func TestReg_CallWithArgsCache(t *testing.T) {
+	r := NewReg().
+		AddArgument("a", 1).
+		AddFunction("test", func(v int) int { return v })
+
+	if got, err := r.CallWithArgs("test", "a"); err != nil || got[0] != 1 {
+		t.Fatalf("Reg.CallWithArgs() = %v, %v, want 1", got, err)
+	}
+
+	// cached plan is compiled again after change
+	r.AddFunction("test", func(v int) int { return v + 1 })
+
+	if got, err := r.CallWithArgs("test", "a"); err != nil || got[0] != 2 {
+		t.Errorf("Reg.CallWithArgs() = %v, %v, want 2", got, err)
+	}
+
+	for i := 0; i < planCacheSize*2; i++ {
+		if _, err := r.CallWithArgs("test", fmt.Sprintf("=%d", i)); err != nil {
+			t.Fatalf("Reg.CallWithArgs() error = %v", err)
+		}
+	}
+
+	if got := r.planCount.Load(); got > planCacheSize {
+		t.Errorf("Reg.CallWithArgs() cached %d plans, want at most %d", got, planCacheSize)
+	}
+}
@@ -0,0 +1,254 @@
+package call
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// provider is an argument backed by a function.
+type provider struct {
+	Func
+	singleton bool
+	// mutex protects singleton value.
+	mutex sync.Mutex
+	done  bool
+	value any
+	// creating is closed when the call creating the value finishes, owner is that call.
+	creating chan struct{}
+	owner    *callRoot
+}
+
+// AddProvider adds function as argument with name, function called on every usage of the argument.
+//
+// First return value of the function is the argument value, trailing error is returned as error.
+// Args are resolved like calling a function so other providers can be used as arguments.
+// Argument must be a function, otherwise it will panic.
+// Name is trimmed like AddArgument.
+func (r *Reg) AddProvider(name string, fn any, args ...string) *Reg {
+	return r.addProvider(name, fn, args, false)
+}
+
+// AddSingleton is like AddProvider but function called once and value is reused.
+//
+// If function returns error, value is not stored and function called again in next usage.
+// Concurrent usages wait the call creating the value, singletons which wait each other
+// in different calls return ErrCircularDependency.
+func (r *Reg) AddSingleton(name string, fn any, args ...string) *Reg {
+	return r.addProvider(name, fn, args, true)
+}
+
+func (r *Reg) addProvider(name string, fn any, args []string, singleton bool) *Reg {
+	fnV := reflect.ValueOf(fn)
+	if fnV.Kind() != reflect.Func {
+		panic("fn argument is not a function")
+	}
+
+	// trim options
+	name = argumentName(name, r.GetDelimeter())
+
+	r.mutex.Lock()
+
+	r.version.Add(1)
+
+	old := r.argumentEntry(name)
+
+	p := &provider{
+		Func: Func{
+			Args: args,
+			Fn:   fnV,
+		},
+		singleton: singleton,
+	}
+
+	delete(r.args, name)
+	r.providers[name] = p
+
+	e := Event{Kind: EventArgument, Name: name, Old: old, New: p.Func}
+	r.queueEvent(e)
+
+	r.mutex.Unlock()
+
+	r.emit()
+
+	return r
+}
+
+// DeleteProvider deletes provider with name.
+func (r *Reg) DeleteProvider(name string) *Reg {
+	r.mutex.Lock()
+
+	r.version.Add(1)
+
+	p, ok := r.providers[name]
+	delete(r.providers, name)
+
+	e := Event{Kind: EventArgument, Name: name, Deleted: true}
+	if ok {
+		e.Old = p.Func
+		r.queueEvent(e)
+	}
+
+	r.mutex.Unlock()
+
+	r.emit()
+
+	return r
+}
+
+// argumentEntry returns argument value or Func of the provider with name, registry should be locked.
+func (r *Reg) argumentEntry(name string) any {
+	if p, ok := r.providers[name]; ok {
+		return p.Func
+	}
+
+	return r.args[name]
+}
+
+// GetProviderNames returns all provider names.
+func (r *Reg) GetProviderNames() []string {
+	return r.visibleNames(
+		func(reg *Reg) []string { return mapKeys(reg.providers) },
+		func(reg *Reg) []string { return mapKeys(reg.args) },
+	)
+}
+
+// release removes singleton value.
+func (p *provider) release() {
+	p.take()
+}
+
+// take removes singleton value and returns it.
+func (p *provider) take() any {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	v := p.value
+	p.value, p.done = nil, false
+
+	return v
+}
+
+// resolveProvider returns value of the provider.
+func (r *Reg) resolveProvider(state *callState, name string, p *provider) (any, error) {
+	for i, n := range state.path {
+		if n == name {
+			cycle := append(append([]string{}, state.path[i:]...), name)
+
+			return nil, fmt.Errorf("%w: %s", ErrCircularDependency, strings.Join(cycle, " -> "))
+		}
+	}
+
+	if !p.singleton {
+		return r.provide(state, name, p)
+	}
+
+	if state.root == nil {
+		state.root = &callRoot{}
+	}
+
+	for {
+		p.mutex.Lock()
+
+		if p.done {
+			v := p.value
+			p.mutex.Unlock()
+
+			return v, nil
+		}
+
+		if p.creating == nil {
+			break
+		}
+
+		// another call is creating the value, wait it without holding the lock
+		creating, owner := p.creating, p.owner
+		p.mutex.Unlock()
+
+		if err := waitSingleton(state.root, owner, creating); err != nil {
+			return nil, fmt.Errorf("%w: %s is created by a call which waits this call", err, name)
+		}
+	}
+
+	creating := make(chan struct{})
+	p.creating, p.owner = creating, state.root
+	p.mutex.Unlock()
+
+	v, err := r.provide(state, name, p)
+
+	p.mutex.Lock()
+	if err == nil {
+		p.value, p.done = v, true
+	}
+
+	p.creating, p.owner = nil, nil
+	close(creating)
+	p.mutex.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	// keep creation order to close values
+	r.mutex.Lock()
+	r.created = append(r.created, created{name: name, provider: p})
+	r.mutex.Unlock()
+
+	return v, nil
+}
+
+// callRoot identifies a call with its nested provider calls, it is used to detect
+// singletons which wait each other in different goroutines.
+type callRoot struct {
+	// waits is the call which creates the singleton this call waits, guarded by waitsMutex.
+	waits *callRoot
+}
+
+var waitsMutex sync.Mutex
+
+// waitSingleton waits singleton created by owner, it returns ErrCircularDependency
+// when owner waits root directly or through other calls.
+func waitSingleton(root, owner *callRoot, creating <-chan struct{}) error {
+	waitsMutex.Lock()
+	for c := owner; c != nil; c = c.waits {
+		if c == root {
+			waitsMutex.Unlock()
+
+			return ErrCircularDependency
+		}
+	}
+
+	root.waits = owner
+	waitsMutex.Unlock()
+
+	<-creating
+
+	waitsMutex.Lock()
+	root.waits = nil
+	waitsMutex.Unlock()
+
+	return nil
+}
+
+// provide calls provider's function and returns first return value.
+func (r *Reg) provide(state *callState, name string, p *provider) (any, error) {
+	nested := *state
+	nested.path = append(append(make([]string, 0, len(state.path)+1), state.path...), name)
+
+	returns, err := r.callFunc(&nested, name, p.Func, p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	returns, err = splitError(returns)
+	if err != nil {
+		return nil, fmt.Errorf("provider %s: %w", name, err)
+	}
+
+	if len(returns) == 0 {
+		return nil, fmt.Errorf("provider %s returns no value", name)
+	}
+
+	return returns[0].Interface(), nil
+}
@@ -0,0 +1,250 @@
+package call
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestReg_AddProvider(t *testing.T) {
+	type db struct {
+		dsn string
+		id  int
+	}
+
+	errOpen := errors.New("open failed")
+
+	tests := []struct {
+		name       string
+		modify     func(r *Reg, counter *int)
+		calls      int
+		want       []any
+		wantCount  int
+		wantErr    error
+		wantErrStr string
+	}{
+		{
+			name: "per call provider",
+			modify: func(r *Reg, counter *int) {
+				r.AddArgument("dsn", "postgres://").
+					AddProvider("db", func(dsn string) *db {
+						*counter++
+
+						return &db{dsn: dsn, id: *counter}
+					}, "dsn")
+			},
+			calls:     2,
+			want:      []any{"postgres://", 2},
+			wantCount: 2,
+		},
+		{
+			name: "singleton provider",
+			modify: func(r *Reg, counter *int) {
+				r.AddArgument("dsn", "postgres://").
+					AddSingleton("db", func(dsn string) (*db, error) {
+						*counter++
+
+						return &db{dsn: dsn, id: *counter}, nil
+					}, "dsn")
+			},
+			calls:     3,
+			want:      []any{"postgres://", 1},
+			wantCount: 1,
+		},
+		{
+			name: "nested providers",
+			modify: func(r *Reg, counter *int) {
+				r.AddProvider("dsn", func() string {
+					*counter++
+
+					return "mysql://"
+				}).
+					AddSingleton("db", func(dsn string) *db {
+						return &db{dsn: dsn}
+					}, "dsn")
+			},
+			calls:     2,
+			want:      []any{"mysql://", 0},
+			wantCount: 1,
+		},
+		{
+			name: "provider error",
+			modify: func(r *Reg, counter *int) {
+				r.AddSingleton("db", func() (*db, error) {
+					*counter++
+
+					return nil, errOpen
+				})
+			},
+			calls:      2,
+			wantCount:  2,
+			wantErr:    errOpen,
+			wantErrStr: "resolve function handler argument db: provider db: open failed",
+		},
+		{
+			name: "cycle",
+			modify: func(r *Reg, _ *int) {
+				r.AddProvider("db", func(dsn string) *db { return &db{dsn: dsn} }, "dsn").
+					AddProvider("dsn", func(c string) string { return c }, "config").
+					AddProvider("config", func(d *db) string { return d.dsn }, "db")
+			},
+			calls:      1,
+			wantErr:    ErrCircularDependency,
+			wantErrStr: "resolve function handler argument db: resolve function db argument dsn: resolve function dsn argument config: resolve function config argument db: circular dependency: db -> dsn -> config -> db",
+		},
+		{
+			name: "no value",
+			modify: func(r *Reg, _ *int) {
+				r.AddProvider("db", func() {})
+			},
+			calls:      1,
+			wantErrStr: "resolve function handler argument db: provider db returns no value",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counter := 0
+
+			r := NewReg().AddFunction("handler", func(d *db) (string, int) {
+				return d.dsn, d.id
+			}, "db")
+			tt.modify(r, &counter)
+
+			var (
+				got []any
+				err error
+			)
+			for i := 0; i < tt.calls; i++ {
+				got, err = r.Call("handler")
+			}
+
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Reg.Call() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErrStr != "" && (err == nil || err.Error() != tt.wantErrStr) {
+				t.Errorf("Reg.Call() error = %v, wantErrStr %v", err, tt.wantErrStr)
+			}
+			if tt.wantErrStr == "" && err != nil {
+				t.Errorf("Reg.Call() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Reg.Call() = %v, want %v", got, tt.want)
+			}
+			if counter != tt.wantCount {
+				t.Errorf("Reg.Call() provider count = %v, want %v", counter, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestReg_Providers(t *testing.T) {
+	r := NewReg().
+		AddArgument("a", 1).
+		AddProvider("b", func() int { return 2 }).
+		AddSingleton("c", func() int { return 3 })
+
+	names := r.GetProviderNames()
+	sort.Strings(names)
+	if want := []string{"b", "c"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Reg.GetProviderNames() = %v, want %v", names, want)
+	}
+
+	// argument replaces provider
+	r.AddArgument("b", 4)
+	if names := r.GetProviderNames(); !reflect.DeepEqual(names, []string{"c"}) {
+		t.Errorf("Reg.GetProviderNames() = %v, want %v", names, []string{"c"})
+	}
+
+	// provider replaces argument
+	r.AddProvider("a", func() int { return 5 })
+	if _, ok := r.GetArgument("a"); ok {
+		t.Errorf("Reg.GetArgument() argument is not replaced")
+	}
+
+	r.DeleteProvider("c")
+	if names := r.GetProviderNames(); !reflect.DeepEqual(names, []string{"a"}) {
+		t.Errorf("Reg.GetProviderNames() = %v, want %v", names, []string{"a"})
+	}
+}
+
+func TestReg_SingletonConcurrentCycle(t *testing.T) {
+	var (
+		mutex   sync.Mutex
+		calls   int
+		arrived = make(chan struct{})
+	)
+
+	// slow lets both singletons start before they resolve each other
+	reg := NewReg().
+		AddProvider("slow", func() int {
+			mutex.Lock()
+			calls++
+			if calls == 2 {
+				close(arrived)
+			}
+			mutex.Unlock()
+
+			<-arrived
+
+			return 0
+		}).
+		AddSingleton("x", func(int, string) string { return "x" }, "slow", "y").
+		AddSingleton("y", func(int, string) string { return "y" }, "slow", "x").
+		AddFunction("fx", func(string) {}, "x").
+		AddFunction("fy", func(string) {}, "y")
+
+	errs := make(chan error, 2)
+
+	for _, name := range []string{"fx", "fy"} {
+		go func(name string) {
+			_, err := reg.Call(name)
+			errs <- err
+		}(name)
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-errs:
+			if !errors.Is(err, ErrCircularDependency) {
+				t.Errorf("Reg.Call() error = %v, want %v", err, ErrCircularDependency)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Reg.Call() is deadlocked")
+		}
+	}
+}
+
+func TestReg_SingletonConcurrent(t *testing.T) {
+	var count atomic.Int32
+
+	release := make(chan struct{})
+
+	reg := NewReg().
+		AddSingleton("s", func() int {
+			<-release
+
+			return int(count.Add(1))
+		}).
+		AddFunction("f", func(v int) int { return v }, "s")
+
+	results := make(chan []any, 4)
+
+	for i := 0; i < 4; i++ {
+		go func() {
+			got, _ := reg.Call("f")
+			results <- got
+		}()
+	}
+
+	close(release)
+
+	for i := 0; i < 4; i++ {
+		if got := <-results; !reflect.DeepEqual(got, []any{1}) {
+			t.Errorf("Reg.Call() = %v, want [1]", got)
+		}
+	}
+}
@@ -1,11 +1,17 @@
 package call
 
 import (
+	"context"
 	"reflect"
-	"strings"
 	"sync"
+	"sync/atomic"
 )
 
+// Option finds and applies options of the arguments.
+//
+// Registry parses argument expressions for Options, other implementations get the
+// argument in VisitOptions (or VisitOptionsContext) and the name is split with their delimeter,
+// so fallbacks, literals and quotes are not available for them.
 type Option interface {
 	GetDelimeter() string
 	AddOption(name string, fn func([]reflect.Value, ...string) ([]reflect.Value, error)) Option
@@ -13,88 +19,240 @@ type Option interface {
 	VisitOptions(arg string, v any) ([]reflect.Value, error)
 }
 
+// OptionContext is implemented by options which pass context of the call to option functions.
+//
+// Options without it get their functions wrapped and context is only checked between options.
+type OptionContext interface {
+	AddOptionContext(name string, fn func(context.Context, []reflect.Value, ...string) ([]reflect.Value, error)) Option
+	GetOptionContext(name string) (func(context.Context, []reflect.Value, ...string) ([]reflect.Value, error), bool)
+	VisitOptionsContext(ctx context.Context, arg string, v any) ([]reflect.Value, error)
+}
+
+// versioned is implemented by options which report their changes, used to invalidate plans.
+type versioned interface {
+	// Version changes when options are added.
+	Version() uint64
+}
+
+// OptionFunc is a named option, FnContext is used instead of Fn when it is set.
 type OptionFunc struct {
-	Name string
-	Fn   func([]reflect.Value, ...string) ([]reflect.Value, error)
+	Name      string
+	Fn        func([]reflect.Value, ...string) ([]reflect.Value, error)
+	FnContext func(context.Context, []reflect.Value, ...string) ([]reflect.Value, error)
 }
 
 type Func struct {
 	Args []string
 	Fn   reflect.Value
+	// Auto resolves parameters by type, Args are used as overrides.
+	Auto bool
 }
 
 // Reg is a registry for functions and arguments.
 type Reg struct {
-	fn    map[string]Func
-	args  map[string]any
-	mutex sync.RWMutex
+	fn         map[string]Func
+	args       map[string]any
+	providers  map[string]*provider
+	conversion bool
+	// conversionSet is true when SetConversion is called, otherwise conversion of the parent is used.
+	conversionSet bool
+	// parent is used for lookups of the scope.
+	parent *Reg
+	// created is singletons in creation order.
+	created []created
+	// owned is arguments closed by the registry.
+	owned []owned
+	// released is singleton values released by Dispose, they are closed in Close.
+	released []owned
+	onStart  []func(context.Context) error
+	onStop   []func(context.Context) error
+	// converters used by "as" and "parse" options.
+	converters *converters
+	middleware []Middleware
+	// fnMiddleware is middleware of the functions by name.
+	fnMiddleware map[string][]Middleware
+	events       eventHandlers
+	// eventQueue is events waiting to be emitted, emitting is true while they are emitted.
+	eventQueue []queuedEvent
+	emitting   bool
+	// version changes on every modification, used to invalidate plans.
+	version atomic.Uint64
+	// plans is compiled plans of the calls by planKey.
+	plans     sync.Map
+	planCount atomic.Int64
+	mutex     sync.RWMutex
 	Option
 }
 
 // NewReg creates new registry.
 func NewReg(optionFuncs ...OptionFunc) *Reg {
-	option := NewOptions().
+	option := newOptions()
+	option.
 		AddOption("index", OptionGetIndex).
-		AddOption("...", OptionVariadic)
+		AddOption("index!", OptionGetIndexStrict).
+		AddOption("...", OptionVariadic).
+		AddOption("keys", OptionKeys).
+		AddOption("values", OptionValues).
+		AddOption("entries", OptionEntries).
+		AddOption("field", OptionField).
+		AddOption("method", OptionMethod).
+		AddOption("path", OptionPath).
+		AddOption("slice", OptionSlice).
+		AddOption("reverse", OptionReverse).
+		AddOption("first", OptionFirst).
+		AddOption("last", OptionLast).
+		AddOption("len", OptionLen).
+		AddOption("filter", OptionFilter).
+		AddOption("default", OptionDefault)
 
 	for _, o := range optionFuncs {
+		if o.FnContext != nil {
+			option.AddOptionContext(o.Name, o.FnContext)
+
+			continue
+		}
+
 		option.AddOption(o.Name, o.Fn)
 	}
 
-	return &Reg{
-		fn:     make(map[string]Func),
-		args:   make(map[string]any),
-		Option: option,
+	r := &Reg{
+		fn:         make(map[string]Func),
+		args:       make(map[string]any),
+		providers:  make(map[string]*provider),
+		converters: newConverters(),
+		Option:     option,
+	}
+
+	// converter options use registry's converters, given options can replace them
+	if _, ok := option.GetOption("as"); !ok {
+		option.AddOption("as", r.OptionAs)
+	}
+
+	if _, ok := option.GetOption("parse"); !ok {
+		option.AddOption("parse", r.OptionParse)
+	}
+
+	return r
+}
+
+// AddOptionContext adds option which gets context of the call.
+//
+// If Option of the registry is not an OptionContext, fn gets context.Background.
+func (r *Reg) AddOptionContext(name string, fn func(context.Context, []reflect.Value, ...string) ([]reflect.Value, error)) Option {
+	if o, ok := r.Option.(OptionContext); ok {
+		return o.AddOptionContext(name, fn)
+	}
+
+	return r.Option.AddOption(name, func(v []reflect.Value, args ...string) ([]reflect.Value, error) {
+		return fn(context.Background(), v, args...)
+	})
+}
+
+// GetOptionContext returns option with name, options without context are wrapped.
+func (r *Reg) GetOptionContext(name string) (func(context.Context, []reflect.Value, ...string) ([]reflect.Value, error), bool) {
+	if o, ok := r.Option.(OptionContext); ok {
+		return o.GetOptionContext(name)
+	}
+
+	fn, ok := r.Option.GetOption(name)
+	if !ok {
+		return nil, false
+	}
+
+	return func(_ context.Context, v []reflect.Value, args ...string) ([]reflect.Value, error) {
+		return fn(v, args...)
+	}, true
+}
+
+// SetConversion enables to convert arguments to the function's parameter types.
+//
+// Conversion follows Go conversion rules like int to int64, string to []byte or
+// named type to underlying type, integer to string conversion is not allowed.
+func (r *Reg) SetConversion(v bool) *Reg {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.version.Add(1)
+
+	r.conversion, r.conversionSet = v, true
+
+	return r
+}
+
+func (r *Reg) isConversion() bool {
+	for reg := r; ; reg = reg.parent {
+		reg.mutex.RLock()
+		conversion, set := reg.conversion, reg.conversionSet
+		reg.mutex.RUnlock()
+
+		if set || reg.parent == nil {
+			return conversion
+		}
 	}
 }
 
 // AddArgument adds argument to registry with name.
 //
 // If name includes delimeter, it will not add options.
+// Names with `|?"\` characters or starting with `=` are referenced with quotes or escapes.
+// Provider with the same name is replaced.
 func (r *Reg) AddArgument(name string, v any) *Reg {
+	// trim options
+	name = argumentName(name, r.GetDelimeter())
+
 	r.mutex.Lock()
-	defer r.mutex.Unlock()
 
-	// trim options
-	name = strings.SplitN(name, r.GetDelimeter(), 2)[0]
+	r.version.Add(1)
+
+	old := r.argumentEntry(name)
 
+	delete(r.providers, name)
 	r.args[name] = v
 
+	e := Event{Kind: EventArgument, Name: name, Old: old, New: v}
+	r.queueEvent(e)
+
+	r.mutex.Unlock()
+
+	r.emit()
+
 	return r
 }
 
 // GetArgument returns argument with name.
 func (r *Reg) GetArgument(name string) (any, bool) {
-	r.mutex.RLock()
-	defer r.mutex.RUnlock()
+	v, p, _, ok := r.lookupArgument(name)
 
-	v, ok := r.args[name]
-
-	return v, ok
+	return v, ok && p == nil
 }
 
 // DeleteArgument deletes argument with name.
 func (r *Reg) DeleteArgument(name string) *Reg {
 	r.mutex.Lock()
-	defer r.mutex.Unlock()
 
+	r.version.Add(1)
+
+	old, ok := r.args[name]
 	delete(r.args, name)
 
+	e := Event{Kind: EventArgument, Name: name, Old: old, Deleted: true}
+	if ok {
+		r.queueEvent(e)
+	}
+
+	r.mutex.Unlock()
+
+	r.emit()
+
 	return r
 }
 
 // GetArgumentNames returns all argument names.
 func (r *Reg) GetArgumentNames() []string {
-	r.mutex.RLock()
-	defer r.mutex.RUnlock()
-
-	names := make([]string, 0, len(r.args))
-
-	for name := range r.args {
-		names = append(names, name)
-	}
-
-	return names
+	return r.visibleNames(
+		func(reg *Reg) []string { return mapKeys(reg.args) },
+		func(reg *Reg) []string { return mapKeys(reg.providers) },
+	)
 }
 
 // AddFunction adds function to registry with name.
@@ -102,56 +260,85 @@ func (r *Reg) GetArgumentNames() []string {
 // If name is empty, function name will be used.
 // Argument must be a function, otherwise it will panic.
 func (r *Reg) AddFunction(name string, fn any, args ...string) *Reg {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
+	return r.addFunction(name, fn, args, false)
+}
 
+func (r *Reg) addFunction(name string, fn any, args []string, auto bool) *Reg {
 	fnV := reflect.ValueOf(fn)
 	if fnV.Kind() != reflect.Func {
 		panic("fn argument is not a function")
 	}
 
+	r.mutex.Lock()
+
+	r.version.Add(1)
+
 	if name == "" {
 		name = getFunctionName(fnV)
 	}
 
-	r.fn[name] = Func{
+	f := Func{
 		Args: args,
 		Fn:   fnV,
+		Auto: auto,
 	}
 
+	e := Event{Kind: EventFunction, Name: name, New: f}
+	if old, ok := r.fn[name]; ok {
+		e.Old = old
+	}
+
+	r.fn[name] = f
+
+	r.queueEvent(e)
+
+	r.mutex.Unlock()
+
+	r.emit()
+
 	return r
 }
 
 // GetFunction returns function with name.
 func (r *Reg) GetFunction(name string) (Func, bool) {
-	r.mutex.RLock()
-	defer r.mutex.RUnlock()
-
-	v, ok := r.fn[name]
+	for reg := r; reg != nil; reg = reg.parent {
+		reg.mutex.RLock()
+		v, ok := reg.fn[name]
+		reg.mutex.RUnlock()
+
+		if ok {
+			return v, true
+		}
+	}
 
-	return v, ok
+	return Func{}, false
 }
 
 // DeleteFunction removes function with name.
 func (r *Reg) DeleteFunction(name string) *Reg {
 	r.mutex.Lock()
-	defer r.mutex.Unlock()
 
+	r.version.Add(1)
+
+	old, ok := r.fn[name]
 	delete(r.fn, name)
 
+	e := Event{Kind: EventFunction, Name: name, Old: old, Deleted: true}
+	if ok {
+		r.queueEvent(e)
+	}
+
+	r.mutex.Unlock()
+
+	r.emit()
+
 	return r
 }
 
 // GetFunctionNames returns all function names.
 func (r *Reg) GetFunctionNames() []string {
-	r.mutex.RLock()
-	defer r.mutex.RUnlock()
-
-	names := make([]string, 0, len(r.fn))
-
-	for name := range r.fn {
-		names = append(names, name)
-	}
-
-	return names
+	return r.visibleNames(
+		func(reg *Reg) []string { return mapKeys(reg.fn) },
+		func(*Reg) []string { return nil },
+	)
 }
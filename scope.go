@@ -0,0 +1,122 @@
+package call
+
+// NewScope returns child registry which falls back to r for arguments, providers and functions.
+//
+// Adding or deleting in the scope doesn't change the parent, scopes can be nested.
+// Options are shared with the parent and conversion follows the parent until SetConversion
+// is called on the scope.
+//
+// Parent's singletons are resolved in the parent, per call providers and functions are
+// resolved in the scope so they can use scope's arguments.
+func (r *Reg) NewScope() *Reg {
+	return &Reg{
+		fn:         make(map[string]Func),
+		args:       make(map[string]any),
+		providers:  make(map[string]*provider),
+		parent:     r,
+		converters: r.converters,
+		Option:     r.Option,
+	}
+}
+
+// Parent returns parent registry of the scope, it is nil for root registry.
+func (r *Reg) Parent() *Reg {
+	return r.parent
+}
+
+// Dispose releases values of the singletons which are added to this registry.
+//
+// Singletons are created again in next usage.
+//
+// Values are not closed, they are kept to be closed in Close.
+func (r *Reg) Dispose() {
+	r.mutex.Lock()
+	providers := make([]*provider, 0, len(r.providers))
+	for _, p := range r.providers {
+		providers = append(providers, p)
+	}
+
+	values := r.created
+	r.created = nil
+	r.mutex.Unlock()
+
+	released := make([]owned, 0, len(values))
+	for _, c := range values {
+		if v := c.provider.take(); v != nil {
+			released = append(released, owned{name: c.name, value: v})
+		}
+	}
+
+	for _, p := range providers {
+		p.release()
+	}
+
+	if len(released) == 0 {
+		return
+	}
+
+	r.mutex.Lock()
+	r.released = append(r.released, released...)
+	r.mutex.Unlock()
+}
+
+// lookupArgument finds argument or provider in registry and its parents.
+//
+// It returns registry which has the provider.
+func (r *Reg) lookupArgument(name string) (any, *provider, *Reg, bool) {
+	for reg := r; reg != nil; reg = reg.parent {
+		reg.mutex.RLock()
+		v, okArg := reg.args[name]
+		p, okProvider := reg.providers[name]
+		reg.mutex.RUnlock()
+
+		if okArg {
+			return v, nil, reg, true
+		}
+
+		if okProvider {
+			return nil, p, reg, true
+		}
+	}
+
+	return nil, nil, nil, false
+}
+
+// visibleNames returns names of the registry and its parents, names which are
+// hidden by the nearer registry are not included.
+func (r *Reg) visibleNames(names func(reg *Reg) []string, hidden func(reg *Reg) []string) []string {
+	seen := make(map[string]struct{})
+	ret := make([]string, 0)
+
+	for reg := r; reg != nil; reg = reg.parent {
+		reg.mutex.RLock()
+		levelNames := names(reg)
+		levelHidden := hidden(reg)
+		reg.mutex.RUnlock()
+
+		for _, name := range levelNames {
+			if _, ok := seen[name]; !ok {
+				ret = append(ret, name)
+			}
+		}
+
+		for _, name := range levelNames {
+			seen[name] = struct{}{}
+		}
+
+		for _, name := range levelHidden {
+			seen[name] = struct{}{}
+		}
+	}
+
+	return ret
+}
+
+func mapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
@@ -0,0 +1,149 @@
+package call
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestReg_NewScope(t *testing.T) {
+	base := NewReg().
+		AddArgument("name", "base").
+		AddArgument("user", "anonymous").
+		AddProvider("greeting", func(name, user string) string { return name + ":" + user }, "name", "user").
+		AddSingleton("config", func(name string) string { return "config-" + name }, "name").
+		AddFunction("greet", func(v string) string { return v }, "greeting")
+
+	scope := base.NewScope().AddArgument("user", "alice")
+	nested := scope.NewScope().AddArgument("name", "nested")
+
+	tests := []struct {
+		name string
+		reg  *Reg
+		fn   string
+		args []string
+		want []any
+	}{
+		{name: "base", reg: base, fn: "greet", want: []any{"base:anonymous"}},
+		{name: "scope overrides argument", reg: scope, fn: "greet", want: []any{"base:alice"}},
+		{name: "nested scope", reg: nested, fn: "greet", want: []any{"nested:alice"}},
+		{name: "singleton resolved in parent", reg: nested, fn: "greet", args: []string{"config"}, want: []any{"config-base"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				got []any
+				err error
+			)
+			if tt.args != nil {
+				got, err = tt.reg.CallWithArgs(tt.fn, tt.args...)
+			} else {
+				got, err = tt.reg.Call(tt.fn)
+			}
+			if err != nil {
+				t.Fatalf("Reg.Call() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Reg.Call() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// writes stay in scope
+	if v, _ := base.GetArgument("user"); v != "anonymous" {
+		t.Errorf("Reg.GetArgument() = %v, want %v", v, "anonymous")
+	}
+
+	nested.DeleteArgument("name")
+	if v, _ := nested.GetArgument("name"); v != "base" {
+		t.Errorf("Reg.GetArgument() = %v, want %v", v, "base")
+	}
+
+	// provider in scope hides parent's argument
+	nested.AddProvider("user", func() string { return "bob" })
+	if _, ok := nested.GetArgument("user"); ok {
+		t.Errorf("Reg.GetArgument() argument should be hidden by provider")
+	}
+
+	names := nested.GetArgumentNames()
+	sort.Strings(names)
+	if want := []string{"name"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Reg.GetArgumentNames() = %v, want %v", names, want)
+	}
+
+	names = nested.GetProviderNames()
+	sort.Strings(names)
+	if want := []string{"config", "greeting", "user"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Reg.GetProviderNames() = %v, want %v", names, want)
+	}
+
+	if names := nested.GetFunctionNames(); !reflect.DeepEqual(names, []string{"greet"}) {
+		t.Errorf("Reg.GetFunctionNames() = %v, want %v", names, []string{"greet"})
+	}
+
+	if nested.Parent() != scope || scope.Parent() != base || base.Parent() != nil {
+		t.Errorf("Reg.Parent() is not correct")
+	}
+}
+
+func TestReg_Dispose(t *testing.T) {
+	counter := 0
+
+	base := NewReg().AddFunction("get", func(v int) int { return v }, "counter")
+	scope := base.NewScope().AddSingleton("counter", func() int {
+		counter++
+
+		return counter
+	})
+
+	for _, want := range []int{1, 1} {
+		if got, err := Invoke[int](scope, "get"); err != nil || got != want {
+			t.Errorf("Invoke() = %v, %v, want %v", got, err, want)
+		}
+	}
+
+	scope.Dispose()
+
+	if got, err := Invoke[int](scope, "get"); err != nil || got != 2 {
+		t.Errorf("Invoke() = %v, %v, want %v", got, err, 2)
+	}
+
+	if _, err := Invoke[int](base, "get"); err == nil {
+		t.Errorf("Invoke() parent should not see scope's singleton")
+	}
+}
+
+func TestReg_ScopeAutowire(t *testing.T) {
+	base := NewReg().
+		AddArgument("name", "base").
+		AddFunctionAuto("get", func(v string) string { return v })
+	scope := base.NewScope().AddArgument("name", "scope")
+
+	if got, err := Invoke[string](scope, "get"); err != nil || got != "scope" {
+		t.Errorf("Invoke() = %v, %v, want %v", got, err, "scope")
+	}
+}
+
+func TestReg_ScopeConversion(t *testing.T) {
+	base := NewReg().
+		AddArgument("n", 1).
+		AddFunction("get", func(v int64) int64 { return v }, "n")
+	scope := base.NewScope()
+
+	base.SetConversion(true)
+
+	// scope follows the parent
+	if got, err := Invoke[int64](scope, "get"); err != nil || got != 1 {
+		t.Errorf("Invoke() = %v, %v, want 1", got, err)
+	}
+
+	scope.SetConversion(false)
+
+	if _, err := Invoke[int64](scope, "get"); err == nil {
+		t.Errorf("Invoke() should fail without conversion in scope")
+	}
+
+	if got, err := Invoke[int64](base, "get"); err != nil || got != 1 {
+		t.Errorf("Invoke() = %v, %v, want 1", got, err)
+	}
+}